@@ -0,0 +1,168 @@
+package platecalc
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rack is a bar in a gym which shares plates with other racks.
+type Rack struct {
+	Name       string
+	Tree       *Tree     // Tree of plate permutations for the bar
+	SetWeights []float32 // Weight for each time step, zero when the rack is not in use
+}
+
+// Conflict is a time step where the loading from the solver for a rack needs
+// more plates than are left over from the racks before it.
+type Conflict struct {
+	Rack         int
+	Step         int
+	Loading      *Tree           // Loading from the solver
+	Shortage     map[float32]int // Number of pairs of each plate missing for Loading
+	Alternatives []*Tree         // Loadings of the same weight which fit, best first
+}
+
+// Allocation is the loading of every rack at each time step.
+type Allocation struct {
+	Loadings  [][]*Tree // Loading for each rack and time step, nil when not in use
+	Conflicts []Conflict
+}
+
+// AllocatePlates solves each rack independently then assigns plates from a
+// shared inventory, one plate per pair, so no plate is used by more than one
+// rack at the same time step. Racks earlier in the list are given priority.
+// When a loading does not fit, the best alternative loading of the same
+// weight from the rack's tree is used and the conflict is reported. Loadings
+// with no alternative are kept as is and reported with their shortage.
+func AllocatePlates(inventory []float32, racks []*Rack, maxDistance int, opts *SolutionOpts) (*Allocation, error) {
+	steps := 0
+	for _, rack := range racks {
+		if len(rack.SetWeights) > steps {
+			steps = len(rack.SetWeights)
+		}
+	}
+
+	alloc := &Allocation{
+		Loadings:  make([][]*Tree, len(racks)),
+		Conflicts: make([]Conflict, 0),
+	}
+
+	for i, rack := range racks {
+		setWeights := make([]float32, 0)
+		for _, weight := range rack.SetWeights {
+			if weight > 0 {
+				setWeights = append(setWeights, weight)
+			}
+		}
+
+		solution := BestSolution(rack.Tree, setWeights, maxDistance, opts)
+		if solution == nil && len(setWeights) > 0 {
+			return nil, fmt.Errorf("no solution found for: %v setWeights=%v", rack.Name, setWeights)
+		}
+
+		alloc.Loadings[i] = make([]*Tree, steps)
+		for step, weight := range rack.SetWeights {
+			if weight > 0 {
+				alloc.Loadings[i][step], solution = solution[0], solution[1:]
+			}
+		}
+	}
+
+	available := countPlates(inventory)
+
+	for step := 0; step < steps; step++ {
+		left := make(map[float32]int)
+		for k, v := range available {
+			left[k] = v
+		}
+
+		prevs := make([]*Tree, len(racks))
+		for i := range racks {
+			for prev := step - 1; prev >= 0 && prevs[i] == nil; prev-- {
+				prevs[i] = alloc.Loadings[i][prev]
+			}
+		}
+
+		for i, rack := range racks {
+			node := alloc.Loadings[i][step]
+			if node == nil {
+				continue
+			}
+
+			shortage := shortage(node, left)
+			if len(shortage) > 0 {
+				conflict := Conflict{
+					Rack:         i,
+					Step:         step,
+					Loading:      node,
+					Shortage:     shortage,
+					Alternatives: alternatives(rack.Tree, node, prevs[i], left, opts),
+				}
+				if len(conflict.Alternatives) > 0 {
+					node = conflict.Alternatives[0]
+					alloc.Loadings[i][step] = node
+				}
+				alloc.Conflicts = append(alloc.Conflicts, conflict)
+			}
+
+			for _, p := range node.Plates() {
+				left[p]--
+			}
+		}
+	}
+
+	return alloc, nil
+}
+
+func countPlates(plates []float32) map[float32]int {
+	counts := make(map[float32]int)
+	for _, p := range plates {
+		counts[p]++
+	}
+	return counts
+}
+
+// shortage returns the number of pairs of each plate needed by node which are
+// not in available.
+func shortage(node *Tree, available map[float32]int) map[float32]int {
+	missing := make(map[float32]int)
+	for p, n := range countPlates(node.Plates()) {
+		if n > available[p] {
+			missing[p] = n - available[p]
+		}
+	}
+	return missing
+}
+
+// alternatives returns every node in tree with the same total weight as node
+// which can be loaded from available, ordered by the score of changing from
+// prev, or by score if prev is nil.
+func alternatives(tree *Tree, node *Tree, prev *Tree, available map[float32]int, opts *SolutionOpts) []*Tree {
+	type candidate struct {
+		node  *Tree
+		score int
+	}
+
+	candidates := make([]candidate, 0)
+	weight := node.TotalWeight()
+	tree.Walk(func(n *Tree) {
+		if n.TotalWeight() != weight || len(shortage(n, available)) > 0 {
+			return
+		}
+		score := n.Score(opts.PreferLessPlates)
+		if prev != nil {
+			score *= prev.Distance(n)
+		}
+		candidates = append(candidates, candidate{n, score})
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	result := make([]*Tree, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.node
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocatePlates(t *testing.T) {
+	newRack := func(name string, setWeights ...float32) *Rack {
+		tree := NewTree(nil, 45)
+		for _, p := range Permutations(45, 25, 10, 10) {
+			tree.Add(p...)
+		}
+		return &Rack{Name: name, Tree: tree, SetWeights: setWeights}
+	}
+
+	racks := []*Rack{
+		newRack("A", 135, 135, 0),
+		newRack("B", 0, 135, 135),
+		newRack("C", 0, 135, 0),
+	}
+	alloc, err := AllocatePlates([]float32{45, 25, 10, 10}, racks, 5, &SolutionOpts{PreferLessPlates: true})
+	assert.NoError(t, err)
+
+	got := make([][]string, len(racks))
+	for i, loadings := range alloc.Loadings {
+		for _, node := range loadings {
+			if node == nil {
+				got[i] = append(got[i], "-")
+			} else {
+				got[i] = append(got[i], node.String())
+			}
+		}
+	}
+
+	want := [][]string{
+		{"45", "45", "-"},
+		{"-", "25, 10, 10", "45"},
+		{"-", "45", "-"},
+	}
+	assert.Equal(t, want, got)
+
+	if assert.Len(t, alloc.Conflicts, 2) {
+		// B uses the alternative loading
+		assert.Equal(t, 1, alloc.Conflicts[0].Rack)
+		assert.Equal(t, 1, alloc.Conflicts[0].Step)
+		assert.Equal(t, map[float32]int{45: 1}, alloc.Conflicts[0].Shortage)
+		assert.Len(t, alloc.Conflicts[0].Alternatives, 3)
+
+		// C has no alternative left and keeps its loading
+		assert.Equal(t, 2, alloc.Conflicts[1].Rack)
+		assert.Equal(t, map[float32]int{45: 1}, alloc.Conflicts[1].Shortage)
+		assert.Empty(t, alloc.Conflicts[1].Alternatives)
+	}
+}
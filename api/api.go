@@ -0,0 +1,566 @@
+// Package api calculates plates for JSON requests from the HTTP server and
+// the WebAssembly module.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kdeloach/platecalc"
+	"github.com/kdeloach/platecalc/plans"
+	"github.com/kdeloach/platecalc/render"
+)
+
+// Limits on requests so a single request can't exhaust the server. The
+// permutation tree grows factorially with the number of plates: 8 plates
+// build in about 0.2s, 9 plates take ten times as long and nearly 1GB.
+const (
+	maxPlates      = 8
+	maxSets        = 20
+	maxMaxDistance = 8
+	// maxReachableSteps limits the weights from min to max checked by
+	// Reachable.
+	maxReachableSteps = 10000
+	// maxCachedTrees limits the permutation trees kept for later requests.
+	maxCachedTrees = 16
+	// MaxBodyBytes is the largest request the server accepts.
+	MaxBodyBytes = 1 << 16
+)
+
+// NoSolutionError is returned when the plates can't load every set weight,
+// or no solution was found before the timeout.
+type NoSolutionError struct {
+	Msg string
+}
+
+func (e *NoSolutionError) Error() string {
+	return e.Msg
+}
+
+// RequestError is returned for an invalid request.
+type RequestError struct {
+	Msg string
+}
+
+func (e *RequestError) Error() string {
+	return e.Msg
+}
+
+func invalid(format string, a ...interface{}) error {
+	return &RequestError{fmt.Sprintf(format, a...)}
+}
+
+// Decode reads a JSON request from r into v, rejecting unknown fields.
+func Decode(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalid("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// BarOptions are the request fields shared by every endpoint.
+type BarOptions struct {
+	Bar              *float32  `json:"bar"`
+	Plates           []float32 `json:"plates"`
+	Implement        string    `json:"implement"`
+	PreferLessPlates bool      `json:"preferLessPlates"`
+	CollarWeight     float32   `json:"collarWeight"`
+	WarmupSets       int       `json:"warmupSets"`
+	WarmupCollars    bool      `json:"warmupCollars"`
+	MaxImbalance     float32   `json:"maxImbalance"`
+}
+
+func (req *BarOptions) validate() error {
+	if len(req.Plates) == 0 {
+		req.Plates = []float32{45, 35, 25, 10, 10, 5, 5, 2.5}
+	}
+	if len(req.Plates) > maxPlates {
+		return invalid("plates: at most %v plates are allowed", maxPlates)
+	}
+	for _, p := range req.Plates {
+		if p <= 0 {
+			return invalid("plates: %v is not a positive weight", p)
+		}
+	}
+	if req.CollarWeight < 0 {
+		return invalid("collarWeight: must not be negative")
+	}
+	if req.WarmupSets < 0 {
+		return invalid("warmupSets: must not be negative")
+	}
+	if req.MaxImbalance < 0 {
+		return invalid("maxImbalance: must not be negative")
+	}
+	return nil
+}
+
+func (req *BarOptions) implement() (platecalc.Implement, error) {
+	return findImplement(req.Implement, req.Bar)
+}
+
+// findImplement returns the implement named name, barbell if empty, weighing
+// bar if it is not nil.
+func findImplement(name string, bar *float32) (platecalc.Implement, error) {
+	if name == "" {
+		name = "barbell"
+	}
+	impl, err := platecalc.FindImplement(name)
+	if err != nil {
+		return impl, invalid("implement: %v", err)
+	}
+	if bar != nil {
+		if *bar < 0 {
+			return impl, invalid("bar: must not be negative")
+		}
+		impl.Weight = *bar
+	}
+	return impl, nil
+}
+
+func (req *BarOptions) opts() *platecalc.SolutionOpts {
+	return &platecalc.SolutionOpts{
+		PreferLessPlates: req.PreferLessPlates,
+		CollarWeight:     req.CollarWeight,
+		WarmupSets:       req.WarmupSets,
+		WarmupCollars:    req.WarmupCollars,
+	}
+}
+
+// tree returns the permutation tree of req.Plates on impl, shared with
+// earlier requests for the same bar and plates.
+func (req *BarOptions) tree(impl platecalc.Implement) *platecalc.Tree {
+	plates := make([]float32, len(req.Plates))
+	copy(plates, req.Plates)
+	sort.Slice(plates, func(i, j int) bool {
+		return plates[i] > plates[j]
+	})
+	key := fmt.Sprintf("%v %v %v %v %v", impl.Name, impl.Weight, impl.Sleeves, plates, req.MaxImbalance)
+	return trees.get(key, func() *platecalc.Tree {
+		tree := platecalc.NewImplementTree(impl.Weight, impl.Sleeves)
+		for _, perm := range platecalc.Permutations(plates...) {
+			tree.Add(perm...)
+		}
+		if req.MaxImbalance > 0 {
+			tree.AddSingles(plates, req.MaxImbalance)
+		}
+		return tree
+	})
+}
+
+// trees holds the permutation trees built for earlier requests. The solvers
+// only read a tree, so concurrent requests may share one.
+var trees = &treeCache{trees: make(map[string]*platecalc.Tree)}
+
+type treeCache struct {
+	mu    sync.Mutex
+	trees map[string]*platecalc.Tree
+}
+
+// get returns the tree for key, calling build if it isn't cached. An
+// arbitrary tree is dropped when the cache is full.
+func (c *treeCache) get(key string, build func() *platecalc.Tree) *platecalc.Tree {
+	c.mu.Lock()
+	tree, ok := c.trees[key]
+	c.mu.Unlock()
+	if ok {
+		return tree
+	}
+
+	tree = build()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.trees) >= maxCachedTrees {
+		for k := range c.trees {
+			delete(c.trees, k)
+			break
+		}
+	}
+	c.trees[key] = tree
+	return tree
+}
+
+// SearchOptions are the request fields for endpoints which search for a
+// sequence of plates.
+type SearchOptions struct {
+	MaxDistance *int `json:"maxDistance"`
+	TimeoutMs   int  `json:"timeoutMs"`
+}
+
+func (req *SearchOptions) validate() error {
+	if req.MaxDistance == nil {
+		d := 5
+		req.MaxDistance = &d
+	}
+	if *req.MaxDistance < 0 || *req.MaxDistance > maxMaxDistance {
+		return invalid("maxDistance: must be between 0 and %v", maxMaxDistance)
+	}
+	if req.TimeoutMs < 0 {
+		return invalid("timeoutMs: must not be negative")
+	}
+	return nil
+}
+
+// timeout returns the requested time to search for a solution, limited to
+// max.
+func (req *SearchOptions) timeout(max time.Duration) time.Duration {
+	if d := time.Duration(req.TimeoutMs) * time.Millisecond; d > 0 && d < max {
+		return d
+	}
+	return max
+}
+
+type CalcRequest struct {
+	BarOptions
+	SearchOptions
+	SetWeights []float32 `json:"setWeights"`
+	Simple     bool      `json:"simple"`
+	SVG        bool      `json:"svg"` // Include an SVG diagram of each set
+}
+
+type Transition struct {
+	Remove []float32 `json:"remove"`
+	Add    []float32 `json:"add"`
+	Text   string    `json:"text"`
+}
+
+type Set struct {
+	Weight  float32    `json:"weight"`
+	Plates  []float32  `json:"plates"`
+	Left    []float32  `json:"left"`
+	Right   []float32  `json:"right"`
+	Text    string     `json:"text"`
+	Changes Transition `json:"changes"`
+	SVG     string     `json:"svg,omitempty"`
+}
+
+type CalcResponse struct {
+	Sets       []Set `json:"sets"`
+	Exhaustive bool  `json:"exhaustive"`
+}
+
+// Calc returns the plates for each set in req, searching until ctx is done or
+// for at most maxTimeout, including the time to build the tree of plates.
+func Calc(ctx context.Context, req *CalcRequest, maxTimeout time.Duration) (*CalcResponse, error) {
+	if err := req.BarOptions.validate(); err != nil {
+		return nil, err
+	}
+	if err := req.SearchOptions.validate(); err != nil {
+		return nil, err
+	}
+	if len(req.SetWeights) == 0 || len(req.SetWeights) > maxSets {
+		return nil, invalid("setWeights: between 1 and %v weights are required", maxSets)
+	}
+	impl, err := req.implement()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, req.timeout(maxTimeout))
+	defer cancel()
+
+	tree := req.tree(impl)
+	opts := req.opts()
+	if ctx.Err() != nil {
+		return nil, &NoSolutionError{"no solution found before timeout"}
+	}
+
+	var solution []*platecalc.Tree
+	var exhaustive bool
+	if req.Simple {
+		solution, exhaustive = platecalc.SimpleSolutionContext(ctx, tree, req.SetWeights, opts)
+	} else {
+		solution, exhaustive = platecalc.BestSolutionContext(ctx, tree, req.SetWeights, *req.MaxDistance, opts)
+	}
+	if solution == nil {
+		if !exhaustive {
+			return nil, &NoSolutionError{"no solution found before timeout"}
+		}
+		return nil, &NoSolutionError{"no solution found"}
+	}
+
+	resp := &CalcResponse{Sets: make([]Set, len(solution)), Exhaustive: exhaustive}
+	transitions := platecalc.Transitions(solution)
+	for i, node := range solution {
+		resp.Sets[i] = Set{
+			Weight:  node.TotalWeight() + opts.Collars(tree, i),
+			Plates:  node.Plates(),
+			Left:    node.Left(),
+			Right:   node.Right(),
+			Text:    node.String(),
+			Changes: newTransition(transitions[i]),
+		}
+		if req.SVG {
+			var prev *platecalc.Tree
+			if i > 0 {
+				prev = solution[i-1]
+			}
+			resp.Sets[i].SVG = render.DiffSVG(prev, node, &render.SVGOpts{Collars: req.CollarWeight > 0})
+		}
+	}
+	return resp, nil
+}
+
+func newTransition(t platecalc.Transition) Transition {
+	resp := Transition{
+		Remove: make([]float32, len(t.Remove)),
+		Add:    make([]float32, len(t.Add)),
+		Text:   t.String(),
+	}
+	for i, node := range t.Remove {
+		resp.Remove[i] = node.Value
+	}
+	for i, node := range t.Add {
+		resp.Add[i] = node.Value
+	}
+	return resp
+}
+
+type ReverseRequest struct {
+	Bar          *float32  `json:"bar"`
+	Implement    string    `json:"implement"`
+	CollarWeight float32   `json:"collarWeight"`
+	Loaded       []float32 `json:"loaded"` // Plates loaded on each sleeve, innermost first
+	Kg           bool      `json:"kg"`     // Weights are in kilograms
+}
+
+type ReverseResponse struct {
+	Weight    float32   `json:"weight"`
+	Pounds    float32   `json:"pounds"`
+	Kilograms float32   `json:"kilograms"`
+	Plates    []float32 `json:"plates"`
+	Text      string    `json:"text"`
+}
+
+// Reverse returns the total weight of the plates loaded on the bar in req.
+func Reverse(req *ReverseRequest) (*ReverseResponse, error) {
+	impl, err := findImplement(req.Implement, req.Bar)
+	if err != nil {
+		return nil, err
+	}
+	if req.Kg && req.Bar == nil {
+		impl.Weight = impl.KgWeight
+	}
+	if req.CollarWeight < 0 {
+		return nil, invalid("collarWeight: must not be negative")
+	}
+	for _, p := range req.Loaded {
+		if p <= 0 {
+			return nil, invalid("loaded: %v is not a positive weight", p)
+		}
+	}
+
+	opts := &platecalc.SolutionOpts{CollarWeight: req.CollarWeight}
+	node, total := platecalc.ReverseSolution(platecalc.NewImplementTree(impl.Weight, impl.Sleeves), req.Loaded, opts)
+
+	resp := &ReverseResponse{
+		Weight:    total,
+		Pounds:    total,
+		Kilograms: platecalc.LbToKg(total),
+		Plates:    node.Plates(),
+		Text:      node.String(),
+	}
+	if req.Kg {
+		resp.Pounds, resp.Kilograms = platecalc.KgToLb(total), total
+	}
+	return resp, nil
+}
+
+type ReachableRequest struct {
+	BarOptions
+	Min       float32 `json:"min"`
+	Max       float32 `json:"max"`
+	Increment float32 `json:"increment"`
+}
+
+type Loading struct {
+	Weight float32   `json:"weight"`
+	Plates []float32 `json:"plates"`
+}
+
+type ReachableResponse struct {
+	Weights []Loading `json:"weights"`
+	Missing []float32 `json:"missing"`
+}
+
+// Reachable returns the simplest loading for every weight the plates in req
+// can load, and the weights which can't be loaded.
+func Reachable(req *ReachableRequest) (*ReachableResponse, error) {
+	if err := req.BarOptions.validate(); err != nil {
+		return nil, err
+	}
+	if req.Min < 0 || req.Max < 0 || req.Increment < 0 {
+		return nil, invalid("min, max, increment: must not be negative")
+	}
+	impl, err := req.implement()
+	if err != nil {
+		return nil, err
+	}
+	opts := req.opts()
+
+	// every loading is a working set, so collars are always on
+	collars := req.CollarWeight * float32(impl.Sleeves)
+	if req.Min == 0 {
+		req.Min = impl.Weight + collars
+	}
+	if req.Max == 0 {
+		req.Max = impl.Weight + collars
+		for _, p := range req.Plates {
+			req.Max += p * float32(impl.Sleeves)
+		}
+	}
+	if req.Increment == 0 {
+		req.Increment = 5
+	}
+	if req.Max < req.Min {
+		return nil, invalid("max: must not be less than min")
+	}
+	if (req.Max-req.Min)/req.Increment > maxReachableSteps {
+		return nil, invalid("increment: at most %v steps from min to max are allowed", maxReachableSteps)
+	}
+
+	tree := platecalc.NewImplementTree(impl.Weight, impl.Sleeves)
+	solution := platecalc.ReachableWeights(tree, req.Plates, req.Min, req.Max, opts)
+
+	resp := &ReachableResponse{Weights: make([]Loading, len(solution))}
+	weights := make([]float32, len(solution))
+	for i, node := range solution {
+		weights[i] = node.TotalWeight() + collars
+		resp.Weights[i] = Loading{Weight: weights[i], Plates: node.Plates()}
+	}
+	resp.Missing = platecalc.MissingWeights(weights, req.Min, req.Max, req.Increment)
+	return resp, nil
+}
+
+type PlanRequest struct {
+	SearchOptions
+	Bar     *float32    `json:"bar"`
+	Profile PlanProfile `json:"profile"`
+	SVG     bool        `json:"svg"` // Add a Diagram column with an SVG of each set
+}
+
+// PlanProfile is the workout plan settings of a PlanRequest, with the same
+// fields as profile.yaml.
+type PlanProfile struct {
+	Plan               string     `json:"Plan"`
+	Plates             string     `json:"Plates"`
+	SquatRepMax        int        `json:"SquatRepMax"`
+	DeadliftRepMax     int        `json:"DeadliftRepMax"`
+	PressRepMax        int        `json:"PressRepMax"`
+	BenchRepMax        int        `json:"BenchRepMax"`
+	TrainingMaxPercent int        `json:"TrainingMaxPercent"`
+	Progression5s      bool       `json:"Progression5s"`
+	PreferLessPlates   bool       `json:"PreferLessPlates"`
+	CollarWeight       float32    `json:"CollarWeight"`
+	WarmupSets         int        `json:"WarmupSets"`
+	WarmupCollars      bool       `json:"WarmupCollars"`
+	MaxImbalance       float32    `json:"MaxImbalance"`
+	SharedBar          [][]string `json:"SharedBar"`
+}
+
+func (p *PlanProfile) settings() *plans.WorkoutPlanSettings {
+	return &plans.WorkoutPlanSettings{
+		Plan:               p.Plan,
+		Plates:             p.Plates,
+		SquatRepMax:        p.SquatRepMax,
+		DeadliftRepMax:     p.DeadliftRepMax,
+		PressRepMax:        p.PressRepMax,
+		BenchRepMax:        p.BenchRepMax,
+		TrainingMaxPercent: p.TrainingMaxPercent,
+		Progression5s:      p.Progression5s,
+		PreferLessPlates:   p.PreferLessPlates,
+		CollarWeight:       p.CollarWeight,
+		WarmupSets:         p.WarmupSets,
+		WarmupCollars:      p.WarmupCollars,
+		MaxImbalance:       p.MaxImbalance,
+		SharedBar:          p.SharedBar,
+	}
+}
+
+type PlanResponse struct {
+	Header     []string   `json:"header"`
+	Rows       [][]string `json:"rows"`
+	Exhaustive bool       `json:"exhaustive"`
+}
+
+// Plan returns the rows of the plan for the profile in req, searching until
+// ctx is done or for at most maxTimeout for the whole plan, including the
+// time to build the tree of plates.
+func Plan(ctx context.Context, req *PlanRequest, maxTimeout time.Duration) (*PlanResponse, error) {
+	if err := req.SearchOptions.validate(); err != nil {
+		return nil, err
+	}
+	settings := req.Profile.settings()
+	if settings.Plates == "" {
+		return nil, invalid("profile.Plates: required")
+	}
+	plates, err := plans.ParsePlates(settings.Plates)
+	if err != nil {
+		return nil, invalid("profile.Plates: %v", err)
+	}
+	bar := BarOptions{
+		Bar:              req.Bar,
+		Plates:           plates,
+		PreferLessPlates: settings.PreferLessPlates,
+		CollarWeight:     settings.CollarWeight,
+		WarmupSets:       settings.WarmupSets,
+		WarmupCollars:    settings.WarmupCollars,
+		MaxImbalance:     settings.MaxImbalance,
+	}
+	if err := bar.validate(); err != nil {
+		return nil, err
+	}
+	impl, err := bar.implement()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, req.timeout(maxTimeout))
+	defer cancel()
+
+	tree := bar.tree(impl)
+	opts := bar.opts()
+	if ctx.Err() != nil {
+		return nil, &NoSolutionError{"no solution found before timeout"}
+	}
+
+	// sessions are solved one at a time, so exhaustive needs no lock
+	settings.Workers = 1
+	exhaustive := true
+	if req.SVG {
+		svgOpts := &render.SVGOpts{Collars: settings.CollarWeight > 0, Width: 240}
+		settings.DrawFn = func(prev, plates *platecalc.Tree) string {
+			return render.DiffSVG(prev, plates, svgOpts)
+		}
+	}
+	settings.PlateCalcFn = func(lifts [][]int) [][]*platecalc.Tree {
+		solution, ok := platecalc.SessionSolutionContext(ctx, tree, plans.LiftWeights(lifts), *req.MaxDistance, opts)
+		exhaustive = exhaustive && ok
+		return solution
+	}
+
+	plan, err := plans.NewWorkoutPlan(settings)
+	if err != nil {
+		return nil, invalid("profile.Plan: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.Write(csv.NewWriter(&buf)); err != nil {
+		if ctx.Err() != nil {
+			return nil, &NoSolutionError{"no solution found before timeout"}
+		}
+		return nil, &NoSolutionError{err.Error()}
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return &PlanResponse{Header: records[0], Rows: records[1:], Exhaustive: exhaustive}, nil
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalc(t *testing.T) {
+	req := &CalcRequest{}
+	err := Decode(strings.NewReader(`{"setWeights": [100, 125], "plates": [45, 25, 10, 5, 2.5]}`), req)
+	assert.Nil(t, err)
+
+	resp, err := Calc(context.Background(), req, time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, resp.Exhaustive)
+	assert.Equal(t, []float32{25, 2.5}, resp.Sets[0].Plates)
+	assert.Equal(t, "remove 2.5; add 10, 5", resp.Sets[1].Changes.Text)
+
+	_, err = Calc(context.Background(), &CalcRequest{SetWeights: []float32{101}}, time.Minute)
+	assert.IsType(t, &NoSolutionError{}, err)
+
+	_, err = Calc(context.Background(), &CalcRequest{}, time.Minute)
+	assert.IsType(t, &RequestError{}, err)
+
+	_, err = Calc(context.Background(), &CalcRequest{
+		BarOptions: BarOptions{Plates: []float32{45, 35, 25, 10, 10, 5, 5, 2.5, 1.25}},
+		SetWeights: []float32{100},
+	}, time.Minute)
+	assert.IsType(t, &RequestError{}, err)
+
+	err = Decode(strings.NewReader(`{"setWeight": [100]}`), req)
+	assert.IsType(t, &RequestError{}, err)
+}
+
+func TestReverse(t *testing.T) {
+	resp, err := Reverse(&ReverseRequest{Loaded: []float32{45, 25, 5}})
+	assert.Nil(t, err)
+	assert.Equal(t, float32(195), resp.Weight)
+	assert.Equal(t, "45, 25, 5", resp.Text)
+
+	bar := float32(20)
+	resp, err = Reverse(&ReverseRequest{Bar: &bar, Loaded: []float32{25}, Kg: true})
+	assert.Nil(t, err)
+	assert.Equal(t, float32(70), resp.Kilograms)
+
+	// the default bar is in kilograms too
+	resp, err = Reverse(&ReverseRequest{Loaded: []float32{25, 10}, CollarWeight: 2.5, Kg: true})
+	assert.Nil(t, err)
+	assert.Equal(t, float32(95), resp.Kilograms)
+
+	_, err = Reverse(&ReverseRequest{Loaded: []float32{-5}})
+	assert.IsType(t, &RequestError{}, err)
+}
+
+func TestReachable(t *testing.T) {
+	resp, err := Reachable(&ReachableRequest{
+		BarOptions: BarOptions{Plates: []float32{25, 10, 5, 5}},
+		Max:        135,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Loading{Weight: 45, Plates: []float32{}}, resp.Weights[0])
+	assert.Equal(t, Loading{Weight: 135, Plates: []float32{25, 10, 5, 5}}, resp.Weights[len(resp.Weights)-1])
+	assert.Equal(t, []float32{50, 60, 70, 80, 90, 100, 110, 120, 130}, resp.Missing)
+
+	// collars are included in every weight
+	resp, err = Reachable(&ReachableRequest{
+		BarOptions: BarOptions{Plates: []float32{25}, CollarWeight: 2.5, WarmupSets: 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Loading{{50, []float32{}}, {100, []float32{25}}}, resp.Weights)
+
+	_, err = Reachable(&ReachableRequest{Max: 1e9, Increment: 0.001})
+	assert.IsType(t, &RequestError{}, err)
+
+	_, err = Reachable(&ReachableRequest{Min: 200, Max: 100})
+	assert.IsType(t, &RequestError{}, err)
+}
+
+func TestPlan(t *testing.T) {
+	req := &PlanRequest{}
+	err := Decode(strings.NewReader(`{"profile": {
+		"Plan": "Wendler531BBB",
+		"Plates": "45,25,10,10,5,5,2.5",
+		"SquatRepMax": 200,
+		"DeadliftRepMax": 220,
+		"PressRepMax": 100,
+		"BenchRepMax": 150,
+		"TrainingMaxPercent": 90
+	}}`), req)
+	assert.Nil(t, err)
+
+	resp, err := Plan(context.Background(), req, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, "Lift", resp.Header[0])
+	assert.Equal(t, "Changes", resp.Header[len(resp.Header)-1])
+	assert.Equal(t, []string{"Squat", "1", "1"}, resp.Rows[0][:3])
+	assert.True(t, resp.Exhaustive)
+
+	// settings which aren't part of a profile are rejected
+	err = Decode(strings.NewReader(`{"profile": {"Plan": "Wendler531BBB", "Workers": 8}}`), req)
+	assert.IsType(t, &RequestError{}, err)
+
+	_, err = Plan(context.Background(), &PlanRequest{Profile: PlanProfile{Plan: "Wendler531BBB"}}, time.Minute)
+	assert.IsType(t, &RequestError{}, err)
+
+	_, err = Plan(context.Background(), &PlanRequest{Profile: PlanProfile{Plan: "Unknown", Plates: "45,25"}}, time.Minute)
+	assert.IsType(t, &RequestError{}, err)
+}
+
+func TestBarOptionsTree(t *testing.T) {
+	impl, err := findImplement("barbell", nil)
+	assert.Nil(t, err)
+
+	// the same plates in any order share a tree
+	tree := (&BarOptions{Plates: []float32{25, 10, 5}}).tree(impl)
+	assert.Same(t, tree, (&BarOptions{Plates: []float32{5, 25, 10}}).tree(impl))
+	assert.NotSame(t, tree, (&BarOptions{Plates: []float32{25, 10, 5}, MaxImbalance: 5}).tree(impl))
+
+	impl.Weight = 35
+	assert.NotSame(t, tree, (&BarOptions{Plates: []float32{25, 10, 5}}).tree(impl))
+}
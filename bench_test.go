@@ -0,0 +1,161 @@
+package platecalc
+
+import (
+	"fmt"
+	"testing"
+)
+
+type benchInventory struct {
+	name   string
+	bar    float32
+	plates []float32
+	sets   map[string][]float32
+}
+
+// Set sequences as solved by the plans: 5/3/1 main sets, 5/3/1 main sets
+// followed by the BBB supplemental weight, and an FSL pyramid.
+var lbSets = map[string][]float32{
+	"531": {135, 155, 175},
+	"bbb": {135, 155, 175, 115},
+	"fsl": {100, 125, 150, 200, 100},
+}
+
+var kgSets = map[string][]float32{
+	"531": {60, 70, 80},
+	"bbb": {60, 70, 80, 50},
+	"fsl": {50, 60, 70, 90, 50},
+}
+
+// maxPermutedPlates is the largest inventory whose permutation tree is built
+// for the benchmarks. A tree of 10 plates already takes seconds and gigabytes
+// to build.
+const maxPermutedPlates = 9
+
+// benchMaxLoaded is the most plates loaded on each side in the trees of
+// inventories larger than maxPermutedPlates.
+const benchMaxLoaded = 5
+
+// Inventories of up to maxPermutedPlates plates.
+var benchInventories = []benchInventory{
+	{"lb6", 45, []float32{45, 25, 10, 10, 5, 2.5}, lbSets},
+	{"lb8", 45, []float32{45, 35, 25, 10, 10, 5, 5, 2.5}, lbSets},
+	{"lb9", 45, []float32{45, 35, 25, 10, 10, 5, 5, 2.5, 1.25}, lbSets},
+	{"kg6", 20, []float32{20, 15, 10, 5, 2.5, 1.25}, kgSets},
+	{"kg8", 20, []float32{25, 20, 15, 10, 5, 2.5, 2.5, 1.25}, kgSets},
+}
+
+// Inventories whose trees only hold loadings of up to benchMaxLoaded plates,
+// which is enough for every set in lbSets and kgSets. These are skipped with
+// -short.
+var benchLargeInventories = []benchInventory{
+	{"lb10", 45, []float32{45, 45, 35, 25, 10, 10, 5, 5, 2.5, 1.25}, lbSets},
+	{"lb12", 45, []float32{45, 45, 35, 25, 25, 10, 10, 5, 5, 2.5, 2.5, 1.25}, lbSets},
+	{"lb14", 45, []float32{45, 45, 45, 35, 25, 25, 10, 10, 5, 5, 2.5, 2.5, 1.25, 1.25}, lbSets},
+	{"kg10", 20, []float32{25, 25, 20, 15, 10, 5, 2.5, 2.5, 1.25, 0.5}, kgSets},
+	{"kg12", 20, []float32{25, 25, 20, 15, 10, 10, 5, 5, 2.5, 2.5, 1.25, 1.25}, kgSets},
+	{"kg14", 20, []float32{25, 25, 25, 20, 15, 10, 10, 5, 5, 2.5, 2.5, 1.25, 1.25, 0.5}, kgSets},
+}
+
+func benchTree(inv benchInventory) *Tree {
+	tree := NewTree(nil, inv.bar)
+	if len(inv.plates) > maxPermutedPlates {
+		addLoadings(tree, nil, inv.plates, benchMaxLoaded)
+		return tree
+	}
+	for _, p := range Permutations(inv.plates...) {
+		tree.Add(p...)
+	}
+	return tree
+}
+
+// addLoadings adds every distinct ordering of up to n more plates after
+// loaded to tree.
+func addLoadings(tree *Tree, loaded, plates []float32, n int) {
+	if n == 0 {
+		return
+	}
+	seen := make(map[float32]bool)
+	for i, p := range plates {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		next := append(loaded[:len(loaded):len(loaded)], p)
+		tree.Add(next...)
+		rest := append(plates[:i:i], plates[i+1:]...)
+		addLoadings(tree, next, rest, n-1)
+	}
+}
+
+// benchSkip skips inventories too large to benchmark with -short.
+func benchSkip(b *testing.B, inv benchInventory) {
+	if testing.Short() && len(inv.plates) > maxPermutedPlates {
+		b.Skip("skipping large inventory with -short")
+	}
+}
+
+func BenchmarkPermutations(b *testing.B) {
+	for _, inv := range benchInventories {
+		b.Run(inv.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Permutations(inv.plates...)
+			}
+		})
+	}
+}
+
+func BenchmarkNewTree(b *testing.B) {
+	for _, inv := range append(benchInventories, benchLargeInventories...) {
+		b.Run(inv.name, func(b *testing.B) {
+			benchSkip(b, inv)
+			for i := 0; i < b.N; i++ {
+				benchTree(inv)
+			}
+		})
+	}
+}
+
+func BenchmarkBestSolution(b *testing.B) {
+	for _, inv := range append(benchInventories, benchLargeInventories...) {
+		tree := benchTree(inv)
+		for _, name := range []string{"531", "bbb", "fsl"} {
+			sets := inv.sets[name]
+			b.Run(fmt.Sprintf("%v/%v", inv.name, name), func(b *testing.B) {
+				benchSkip(b, inv)
+				for i := 0; i < b.N; i++ {
+					if BestSolution(tree, sets, 5, &SolutionOpts{}) == nil {
+						b.Fatalf("no solution found for setWeights=%v", sets)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkSimpleSolution(b *testing.B) {
+	for _, inv := range append(benchInventories, benchLargeInventories...) {
+		tree := benchTree(inv)
+		for _, name := range []string{"531", "bbb", "fsl"} {
+			sets := inv.sets[name]
+			b.Run(fmt.Sprintf("%v/%v", inv.name, name), func(b *testing.B) {
+				benchSkip(b, inv)
+				for i := 0; i < b.N; i++ {
+					if SimpleSolution(tree, sets, &SolutionOpts{}) == nil {
+						b.Fatalf("no solution found for setWeights=%v", sets)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkReachableWeights(b *testing.B) {
+	for _, inv := range append(benchInventories, benchLargeInventories...) {
+		b.Run(inv.name, func(b *testing.B) {
+			benchSkip(b, inv)
+			for i := 0; i < b.N; i++ {
+				ReachableWeights(NewTree(nil, inv.bar), inv.plates, 0, 1000, &SolutionOpts{})
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+"use strict";
+
+// Settings are saved in localStorage under this key after every change.
+const storageKey = "platecalc";
+// The last generated plan is saved under this key so it can be viewed offline.
+const planKey = "platecalc.plan";
+// version is stamped by `make pwa`, as in sw.js. The service worker is only
+// registered for a stamped build so files served from bin are never cached.
+const version = "dev";
+
+const defaults = {
+    bar: {
+        implement: "barbell",
+        // null uses the implement's default weight
+        bar: null,
+        plates: "45, 35, 25, 10, 10, 5, 5, 2.5",
+        collarWeight: 0,
+        warmupSets: 0,
+        maxImbalance: 0,
+        warmupCollars: false,
+        preferLessPlates: false,
+    },
+    calc: {
+        setWeights: "135, 185, 225",
+        simple: false,
+    },
+    // the bar, plates and collars of a plan are set in bar
+    profile: {
+        Plan: "Wendler531BBB",
+        SquatRepMax: 300,
+        DeadliftRepMax: 310,
+        PressRepMax: 145,
+        BenchRepMax: 205,
+        TrainingMaxPercent: 90,
+        Progression5s: false,
+    },
+};
+
+function load() {
+    let saved = {};
+    try {
+        saved = JSON.parse(localStorage.getItem(storageKey)) || {};
+    } catch (err) {
+        // ignore settings which can't be read
+    }
+    const settings = {};
+    for (const name of Object.keys(defaults)) {
+        settings[name] = {};
+        // settings which are no longer used are dropped
+        for (const key of Object.keys(defaults[name])) {
+            const values = saved[name] || {};
+            settings[name][key] = key in values ? values[key] : defaults[name][key];
+        }
+    }
+    return settings;
+}
+
+function save(settings) {
+    localStorage.setItem(storageKey, JSON.stringify(settings));
+}
+
+// bindForm fills the form from values and updates values, and saves
+// settings, whenever an input changes.
+function bindForm(form, values, settings) {
+    for (const input of form.elements) {
+        if (!input.name || !(input.name in values)) {
+            continue;
+        }
+        if (input.type === "checkbox") {
+            input.checked = values[input.name];
+        } else {
+            input.value = values[input.name] === null ? "" : values[input.name];
+        }
+        input.addEventListener("change", function() {
+            if (input.type === "checkbox") {
+                values[input.name] = input.checked;
+            } else if (input.type === "number") {
+                values[input.name] = input.value === "" ? null : Number(input.value);
+            } else {
+                values[input.name] = input.value;
+            }
+            save(settings);
+        });
+    }
+}
+
+// parseWeights parses a list of weights separated by commas or spaces.
+function parseWeights(s) {
+    return s.split(/[\s,]+/).filter(Boolean).map(w => {
+        const n = Number(w);
+        if (isNaN(n)) {
+            throw new Error(`invalid weight: ${w}`);
+        }
+        return n;
+    });
+}
+
+function barRequest(bar) {
+    const req = {
+        implement: bar.implement,
+        plates: parseWeights(bar.plates),
+        collarWeight: bar.collarWeight || 0,
+        warmupSets: bar.warmupSets || 0,
+        maxImbalance: bar.maxImbalance || 0,
+        warmupCollars: bar.warmupCollars,
+        preferLessPlates: bar.preferLessPlates,
+    };
+    if (bar.bar !== null && bar.bar !== undefined) {
+        req.bar = bar.bar;
+    }
+    return req;
+}
+
+// The WebAssembly module runs in a worker so the page stays responsive while
+// it searches. Each request is answered by the pending promise with its id.
+let worker = null;
+let nextId = 0;
+const pending = new Map();
+
+// startWorker loads platecalc.wasm in a worker and resolves once it is ready.
+function startWorker() {
+    return new Promise((resolve, reject) => {
+        worker = new Worker("worker.js");
+        worker.addEventListener("message", event => {
+            const msg = event.data;
+            if ("ready" in msg) {
+                if (msg.ready) {
+                    resolve();
+                } else {
+                    reject(new Error(msg.error));
+                }
+                return;
+            }
+            const {resolve: done, reject: fail} = pending.get(msg.id);
+            pending.delete(msg.id);
+            if (msg.error) {
+                const err = new Error(msg.error.message);
+                err.code = msg.error.code;
+                fail(err);
+            } else {
+                done(msg.resp);
+            }
+        });
+        worker.addEventListener("error", event => reject(new Error(event.message)));
+    });
+}
+
+// solve posts req for the platecalc function named method to the worker.
+function solve(method, req) {
+    return new Promise((resolve, reject) => {
+        const id = nextId++;
+        pending.set(id, {resolve, reject});
+        worker.postMessage({id, method, req});
+    });
+}
+
+function showError(el, err) {
+    el.textContent = err.message;
+    el.hidden = false;
+}
+
+function cell(row, text) {
+    const td = row.insertCell();
+    td.textContent = text;
+    return td;
+}
+
+// busy disables the buttons of form until promise settles.
+async function busy(form, promise) {
+    const buttons = form.querySelectorAll("button");
+    buttons.forEach(b => b.disabled = true);
+    document.getElementById("status").textContent = "Calculating…";
+    try {
+        return await promise;
+    } finally {
+        buttons.forEach(b => b.disabled = false);
+        document.getElementById("status").textContent = "";
+    }
+}
+
+async function calc(settings) {
+    const form = document.getElementById("calc");
+    const errorEl = document.getElementById("calc-error");
+    const table = document.getElementById("calc-result");
+    const tbody = table.tBodies[0];
+    errorEl.hidden = true;
+
+    let resp;
+    try {
+        const req = Object.assign(barRequest(settings.bar), {
+            setWeights: parseWeights(settings.calc.setWeights),
+            simple: settings.calc.simple,
+            svg: true,
+        });
+        resp = await busy(form, solve("calc", req));
+    } catch (err) {
+        table.hidden = true;
+        showError(errorEl, err);
+        return;
+    }
+
+    tbody.replaceChildren();
+    for (const set of resp.sets) {
+        const row = tbody.insertRow();
+        cell(row, set.weight);
+        cell(row, set.text || "empty bar");
+        cell(row, set.changes.text);
+        // the diagram is generated by platecalc, not from user input
+        row.insertCell().innerHTML = set.svg;
+    }
+    table.hidden = false;
+    document.getElementById("calc-warning").hidden = resp.exhaustive;
+}
+
+// plan holds the last generated plan for downloading.
+let plan = null;
+
+async function generatePlan(settings) {
+    const form = document.getElementById("profile");
+    const errorEl = document.getElementById("plan-error");
+    const table = document.getElementById("plan-result");
+    errorEl.hidden = true;
+
+    try {
+        // plans are lifted on a barbell with the plates and collars from bar
+        const bar = barRequest(settings.bar);
+        if (bar.implement !== "barbell") {
+            throw new Error("Plans are for a barbell; choose Barbell under Bar");
+        }
+        const profile = Object.assign({}, settings.profile, {
+            Plates: bar.plates.join(","),
+            CollarWeight: bar.collarWeight,
+            WarmupSets: bar.warmupSets,
+            WarmupCollars: bar.warmupCollars,
+            MaxImbalance: bar.maxImbalance,
+            PreferLessPlates: bar.preferLessPlates,
+        });
+        for (const [k, v] of Object.entries(profile)) {
+            if (v === null) {
+                delete profile[k];
+            }
+        }
+        // a plan is many searches; they run in the worker, so the page can
+        // wait longer than a server would
+        const req = {profile, svg: true, timeoutMs: 30000};
+        if ("bar" in bar) {
+            req.bar = bar.bar;
+        }
+        plan = await busy(form, solve("plan", req));
+    } catch (err) {
+        plan = null;
+        table.hidden = true;
+        document.getElementById("plan-warning").hidden = true;
+        showError(errorEl, err);
+        document.getElementById("download").disabled = true;
+        return;
+    }
+    renderPlan(plan);
+    try {
+        localStorage.setItem(planKey, JSON.stringify(plan));
+    } catch (err) {
+        // the plan is too large to save; it can still be downloaded
+    }
+}
+
+function loadPlan() {
+    try {
+        return JSON.parse(localStorage.getItem(planKey));
+    } catch (err) {
+        return null;
+    }
+}
+
+// withoutDiagram returns the plan without the Diagram column.
+function withoutDiagram(plan) {
+    const i = plan.header.indexOf("Diagram");
+    if (i < 0) {
+        return plan;
+    }
+    const drop = row => row.filter((_, j) => j !== i);
+    return {header: drop(plan.header), rows: plan.rows.map(drop)};
+}
+
+function renderPlan(plan) {
+    const table = document.getElementById("plan-result");
+    const diagram = plan.header.indexOf("Diagram");
+
+    const thead = table.tHead;
+    thead.replaceChildren();
+    const head = thead.insertRow();
+    for (const name of plan.header) {
+        const th = document.createElement("th");
+        th.textContent = name;
+        head.appendChild(th);
+    }
+
+    const tbody = table.tBodies[0];
+    tbody.replaceChildren();
+    for (const values of plan.rows) {
+        const row = tbody.insertRow();
+        values.forEach((v, i) => {
+            if (i === diagram) {
+                row.insertCell().innerHTML = v;
+            } else {
+                cell(row, v);
+            }
+        });
+    }
+    table.hidden = false;
+    // plans saved before exhaustive was reported have no warning
+    document.getElementById("plan-warning").hidden = plan.exhaustive !== false;
+    document.getElementById("download").disabled = false;
+}
+
+function csvField(s) {
+    return /[",\n]/.test(s) ? `"${s.replace(/"/g, '""')}"` : s;
+}
+
+function downloadPlan() {
+    if (!plan) {
+        return;
+    }
+    const p = withoutDiagram(plan);
+    const csv = [p.header, ...p.rows].map(row => row.map(csvField).join(",")).join("\n") + "\n";
+    const a = document.createElement("a");
+    a.href = URL.createObjectURL(new Blob([csv], {type: "text/csv"}));
+    a.download = `${document.querySelector("#profile [name=Plan]").value}.csv`;
+    a.click();
+    URL.revokeObjectURL(a.href);
+}
+
+async function main() {
+    const settings = load();
+    bindForm(document.getElementById("bar"), settings.bar, settings);
+    bindForm(document.getElementById("calc"), settings.calc, settings);
+    bindForm(document.getElementById("profile"), settings.profile, settings);
+
+    document.getElementById("calc").addEventListener("submit", e => {
+        e.preventDefault();
+        calc(settings);
+    });
+    document.getElementById("profile").addEventListener("submit", e => {
+        e.preventDefault();
+        generatePlan(settings);
+    });
+    document.getElementById("download").addEventListener("click", downloadPlan);
+
+    plan = loadPlan();
+    if (plan) {
+        renderPlan(plan);
+    }
+
+    if ("serviceWorker" in navigator && version !== "dev") {
+        navigator.serviceWorker.register("sw.js").catch(err => {
+            console.log(`service worker not registered: ${err.message}`);
+        });
+    } else if ("serviceWorker" in navigator) {
+        // drop a worker left by a bundle served from the same origin
+        navigator.serviceWorker.getRegistrations().then(registrations => {
+            for (const registration of registrations) {
+                registration.unregister();
+            }
+        });
+    }
+
+    const status = document.getElementById("status");
+    try {
+        await startWorker();
+    } catch (err) {
+        status.textContent = `Failed to load platecalc.wasm: ${err.message}`;
+        return;
+    }
+    status.textContent = "";
+    document.querySelectorAll("#calc button[type=submit], #profile button[type=submit]")
+        .forEach(b => b.disabled = false);
+}
+
+main();
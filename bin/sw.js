@@ -0,0 +1,44 @@
+"use strict";
+
+// version is replaced with a hash of the app's files by `make pwa` so a new
+// build replaces the old cache.
+const version = "dev";
+const cacheName = `platecalc-${version}`;
+
+const files = [
+    ".",
+    "index.html",
+    "app.js",
+    "worker.js",
+    "style.css",
+    "manifest.webmanifest",
+    "icon.svg",
+    "wasm_exec.js",
+    "platecalc.wasm",
+];
+
+self.addEventListener("install", event => {
+    event.waitUntil(
+        caches.open(cacheName)
+            .then(cache => cache.addAll(files))
+            .then(() => self.skipWaiting()));
+});
+
+self.addEventListener("activate", event => {
+    event.waitUntil(
+        caches.keys()
+            .then(keys => Promise.all(keys
+                .filter(key => key.startsWith("platecalc-") && key !== cacheName)
+                .map(key => caches.delete(key))))
+            .then(() => self.clients.claim()));
+});
+
+// Files are served from the cache first so the app starts without a network.
+self.addEventListener("fetch", event => {
+    if (event.request.method !== "GET") {
+        return;
+    }
+    event.respondWith(
+        caches.match(event.request, {ignoreSearch: true})
+            .then(cached => cached || fetch(event.request)));
+});
@@ -0,0 +1,29 @@
+"use strict";
+
+// The WebAssembly module runs in this worker so searches, which block until
+// they finish, don't freeze the page. Requests are posted as
+// {id, method, req} and answered with {id, resp} or {id, error}. Once the
+// module is loaded, {ready: true} is posted, or {ready: false, error} if it
+// failed to load.
+importScripts("wasm_exec.js");
+
+const loaded = (async () => {
+    const go = new Go();
+    const result = await WebAssembly.instantiateStreaming(fetch("platecalc.wasm"), go.importObject);
+    go.run(result.instance);
+})();
+
+loaded.then(
+    () => self.postMessage({ready: true}),
+    err => self.postMessage({ready: false, error: err.message}));
+
+self.addEventListener("message", async event => {
+    const {id, method, req} = event.data;
+    try {
+        await loaded;
+        const resp = await platecalc[method](req);
+        self.postMessage({id, resp});
+    } catch (err) {
+        self.postMessage({id, error: {message: err.message, code: err.code}});
+    }
+});
@@ -0,0 +1,189 @@
+package platecalc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// SolutionCache memoizes solutions keyed by bar, plate inventory, solver
+// options and set weights. Solutions are stored as plate lists so they can be
+// saved to disk and resolved against any tree built from the same inventory.
+// Plates loaded on one side only are stored as negative values.
+// A SolutionCache is safe for concurrent use by multiple goroutines.
+type SolutionCache struct {
+	mu      sync.Mutex
+	entries map[string][][]float32
+	Hits    int
+	Misses  int
+}
+
+func NewSolutionCache() *SolutionCache {
+	return &SolutionCache{
+		entries: make(map[string][][]float32),
+	}
+}
+
+// BestSolutionContext is the cached equivalent of BestSolutionContext, where
+// plates is the inventory tree was built from. Solutions from searches which
+// were not exhaustive are not cached.
+func (c *SolutionCache) BestSolutionContext(ctx context.Context, tree *Tree, plates []float32, setWeights []float32, maxDistance int, opts *SolutionOpts) ([]*Tree, bool) {
+	key := cacheKey(tree, plates, setWeights, maxDistance, opts)
+	return c.solve(key, tree, func() ([]*Tree, bool) {
+		return BestSolutionContext(ctx, tree, setWeights, maxDistance, opts)
+	})
+}
+
+// SessionSolutionContext is the cached equivalent of SessionSolutionContext,
+// where plates is the inventory tree was built from. A session of one lift
+// shares its cache entry with BestSolutionContext.
+func (c *SolutionCache) SessionSolutionContext(ctx context.Context, tree *Tree, plates []float32, lifts [][]float32, maxDistance int, opts *SolutionOpts) ([][]*Tree, bool) {
+	setWeights := make([]float32, 0)
+	lengths := make([]int, len(lifts))
+	for i, sets := range lifts {
+		setWeights = append(setWeights, sets...)
+		lengths[i] = len(sets)
+	}
+	key := cacheKey(tree, plates, setWeights, maxDistance, opts)
+	if len(lifts) > 1 {
+		// warm-up sets are counted from the first set of each lift
+		key += fmt.Sprintf(" lifts=%v", lengths)
+	}
+
+	solution, exhaustive := c.solve(key, tree, func() ([]*Tree, bool) {
+		result, exhaustive := SessionSolutionContext(ctx, tree, lifts, maxDistance, opts)
+		if result == nil {
+			return nil, exhaustive
+		}
+		solution := make([]*Tree, 0, len(setWeights))
+		for _, nodes := range result {
+			solution = append(solution, nodes...)
+		}
+		return solution, exhaustive
+	})
+	if solution == nil {
+		return nil, exhaustive
+	}
+	return SplitSolution(solution, lengths), exhaustive
+}
+
+// solve returns the cached solution for key, or the solution found by search
+// if there is none.
+func (c *SolutionCache) solve(key string, tree *Tree, search func() ([]*Tree, bool)) ([]*Tree, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		if solution, ok := resolve(tree, entry); ok {
+			c.Hits++
+			c.mu.Unlock()
+			return solution, true
+		}
+	}
+	c.Misses++
+	c.mu.Unlock()
+
+	solution, exhaustive := search()
+	if exhaustive {
+		var entry [][]float32
+		if solution != nil {
+			entry = make([][]float32, len(solution))
+			for i, node := range solution {
+				entry[i] = node.Plates()
+				if !node.Symmetric() {
+					entry[i][len(entry[i])-1] = -node.Value
+				}
+			}
+		}
+		c.mu.Lock()
+		c.entries[key] = entry
+		c.mu.Unlock()
+	}
+	return solution, exhaustive
+}
+
+// HitRate returns the percent of lookups which were found in the cache.
+func (c *SolutionCache) HitRate() float32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Hits+c.Misses == 0 {
+		return 0
+	}
+	return float32(c.Hits) / float32(c.Hits+c.Misses) * 100
+}
+
+// Load adds the solutions saved in path to the cache. A missing file is not
+// an error.
+func (c *SolutionCache) Load(path string) error {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string][][]float32)
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range entries {
+		c.entries[k] = v
+	}
+	return nil
+}
+
+// Save writes every solution in the cache to path.
+func (c *SolutionCache) Save(path string) error {
+	c.mu.Lock()
+	buf, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+func cacheKey(tree *Tree, plates []float32, setWeights []float32, maxDistance int, opts *SolutionOpts) string {
+	inventory := append([]float32{}, plates...)
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i] > inventory[j] })
+	imbalance := float32(0)
+	for _, child := range tree.children {
+		if child.Sleeves < tree.Sleeves && child.Value > imbalance {
+			imbalance = child.Value
+		}
+	}
+	return fmt.Sprintf("bar=%v sleeves=%v plates=%v imbalance=%v maxdistance=%v less=%v collar=%v warmups=%v warmupcollars=%v sets=%v",
+		tree.Value, tree.Sleeves, inventory, imbalance, maxDistance, opts.PreferLessPlates, opts.CollarWeight, opts.WarmupSets, opts.WarmupCollars, setWeights)
+}
+
+// resolve returns the nodes of tree for each plate list in entry.
+func resolve(tree *Tree, entry [][]float32) ([]*Tree, bool) {
+	if entry == nil {
+		return nil, true
+	}
+	solution := make([]*Tree, len(entry))
+	for i, plates := range entry {
+		node := tree
+		if n := len(plates); n > 0 && plates[n-1] < 0 {
+			if n > 1 {
+				node = tree.Find(plates[:n-1]...)
+			}
+			if node != nil {
+				node = node.FindSingle(-plates[n-1])
+			}
+		} else if n > 0 {
+			node = tree.Find(plates...)
+		}
+		if node == nil {
+			return nil, false
+		}
+		solution[i] = node
+	}
+	return solution, true
+}
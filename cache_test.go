@@ -0,0 +1,149 @@
+package platecalc
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolutionCache(t *testing.T) {
+	plates := []float32{5, 5, 10, 10, 2.5}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	ctx := context.Background()
+	opts := &SolutionOpts{}
+	sets := []float32{55, 65, 75, 55}
+	want := BestSolution(tree, sets, 5, opts)
+
+	cache := NewSolutionCache()
+	got, exhaustive := cache.BestSolutionContext(ctx, tree, plates, sets, 5, opts)
+	assert.True(t, exhaustive)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 0, cache.Hits)
+
+	got, _ = cache.BestSolutionContext(ctx, tree, plates, sets, 5, opts)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 1, cache.Hits)
+	assert.Equal(t, float32(50), cache.HitRate())
+
+	// different options should not share entries
+	cache.BestSolutionContext(ctx, tree, plates, sets, 5, &SolutionOpts{PreferLessPlates: true})
+	assert.Equal(t, 2, cache.Misses)
+
+	// unreachable weights are cached as no solution
+	got, _ = cache.BestSolutionContext(ctx, tree, plates, []float32{1000}, 5, opts)
+	assert.Nil(t, got)
+	got, _ = cache.BestSolutionContext(ctx, tree, plates, []float32{1000}, 5, opts)
+	assert.Nil(t, got)
+	assert.Equal(t, 2, cache.Hits)
+}
+
+func TestSolutionCacheSession(t *testing.T) {
+	plates := []float32{5, 5, 10, 10, 2.5}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	ctx := context.Background()
+	opts := &SolutionOpts{CollarWeight: 2.5, WarmupSets: 1}
+	lifts := [][]float32{{55, 65}, {75, 55}}
+	want := SessionSolution(tree, lifts, 5, opts)
+
+	cache := NewSolutionCache()
+	got, exhaustive := cache.SessionSolutionContext(ctx, tree, plates, lifts, 5, opts)
+	assert.True(t, exhaustive)
+	assert.Equal(t, want, got)
+	got, _ = cache.SessionSolutionContext(ctx, tree, plates, lifts, 5, opts)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 1, cache.Hits)
+
+	// the same sets split into different lifts have different warm-up sets
+	cache.SessionSolutionContext(ctx, tree, plates, [][]float32{{55, 65, 75, 55}}, 5, opts)
+	assert.Equal(t, 2, cache.Misses)
+
+	// a session of one lift is the same search as BestSolutionContext
+	cache.BestSolutionContext(ctx, tree, plates, []float32{55, 65, 75, 55}, 5, opts)
+	assert.Equal(t, 2, cache.Hits)
+}
+
+func TestSolutionCacheSave(t *testing.T) {
+	plates := []float32{5, 5, 10, 10, 2.5}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	opts := &SolutionOpts{}
+	sets := []float32{55, 65, 75, 55}
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache := NewSolutionCache()
+	want, _ := cache.BestSolutionContext(context.Background(), tree, plates, sets, 5, opts)
+	assert.NoError(t, cache.Save(path))
+
+	// load into a new tree built from the same inventory
+	other := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		other.Add(p...)
+	}
+	loaded := NewSolutionCache()
+	assert.NoError(t, loaded.Load(path))
+	got, _ := loaded.BestSolutionContext(context.Background(), other, plates, sets, 5, opts)
+	assert.Equal(t, 1, loaded.Hits)
+	for i := range want {
+		assert.Equal(t, want[i].String(), got[i].String())
+	}
+
+	assert.NoError(t, NewSolutionCache().Load(filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestSolutionCacheEmptyBar(t *testing.T) {
+	plates := []float32{5, 5, 10, 10}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	// a set of the bar weight alone is solved by the root, which has no parent
+	opts := &SolutionOpts{}
+	sets := []float32{45, 55, 45}
+	want := BestSolution(tree, sets, 5, opts)
+	assert.Equal(t, tree, want[0])
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache := NewSolutionCache()
+	got, _ := cache.BestSolutionContext(context.Background(), tree, plates, sets, 5, opts)
+	assert.Equal(t, want, got)
+	assert.NoError(t, cache.Save(path))
+
+	loaded := NewSolutionCache()
+	assert.NoError(t, loaded.Load(path))
+	got, _ = loaded.BestSolutionContext(context.Background(), tree, plates, sets, 5, opts)
+	assert.Equal(t, 1, loaded.Hits)
+	assert.Equal(t, want, got)
+}
+
+func TestSolutionCacheAsymmetric(t *testing.T) {
+	plates := []float32{10, 5, 2.5}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+	tree.AddSingles(plates, 2.5)
+
+	opts := &SolutionOpts{}
+	sets := []float32{45, 55, 57.5, 60}
+	want := BestSolution(tree, sets, 5, opts)
+
+	cache := NewSolutionCache()
+	cache.BestSolutionContext(context.Background(), tree, plates, sets, 5, opts)
+	got, _ := cache.BestSolutionContext(context.Background(), tree, plates, sets, 5, opts)
+	assert.Equal(t, 1, cache.Hits)
+	assert.Equal(t, want, got)
+}
@@ -1,13 +1,53 @@
 package platecalc
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
+// cancelCheckInterval is the number of search steps between checks for
+// cancellation of the solver context.
+const cancelCheckInterval = 256
+
 type SolutionOpts struct {
-	Debug            bool
-	PreferLessPlates bool // Prefer less/heavier over more/lighter plates
+	Debug            bool    // Print each better solution found to stderr
+	PreferLessPlates bool    // Prefer less/heavier over more/lighter plates
+	CollarWeight     float32 // Weight of each collar (zero if collars are not used)
+	WarmupSets       int     // Number of leading sets which are warm-up sets
+	WarmupCollars    bool    // Use collars on warm-up sets
+}
+
+// Collars returns the combined weight of the collars on the bar of tree for
+// the set at index i of setWeights.
+func (opts *SolutionOpts) Collars(tree *Tree, i int) float32 {
+	if i < opts.WarmupSets && !opts.WarmupCollars {
+		return 0
+	}
+	return opts.collarWeight(tree)
+}
+
+// collarWeight returns the combined weight of a collar on every sleeve of the
+// bar of tree, which may be any node of the tree.
+func (opts *SolutionOpts) collarWeight(tree *Tree) float32 {
+	for tree.Parent != nil {
+		tree = tree.Parent
+	}
+	return opts.CollarWeight * float32(tree.Sleeves)
+}
+
+// loadWeights returns the bar and plate weight needed for each set in
+// setWeights after removing the weight of the collars on the bar of tree.
+func (opts *SolutionOpts) loadWeights(tree *Tree, setWeights []float32) []float32 {
+	weights := make([]float32, len(setWeights))
+	for i, weight := range setWeights {
+		weights[i] = weight - opts.Collars(tree, i)
+	}
+	return weights
 }
 
 // Permutations returns every possible combination as tuples of length 1 to N.
@@ -36,24 +76,48 @@ func Permutations(plates ...float32) [][]float32 {
 
 // BestSolution returns the optimal sequence of plate changes for setWeights
 // by walking the permutation tree and selecting the closest nodes with the
-// lowest combined score.
+// lowest combined score. Collars are included in the total weight of each set
+// according to opts.
+//
+// Ties are broken in favor of the sequence with fewer plates on the bar in
+// total, then by the order in which the tree is walked, so the same tree and
+// setWeights always produce the same solution.
 func BestSolution(tree *Tree, setWeights []float32, maxDistance int, opts *SolutionOpts) []*Tree {
+	solution, _ := BestSolutionContext(context.Background(), tree, setWeights, maxDistance, opts)
+	return solution
+}
+
+// BestSolutionContext is BestSolution but stops searching once ctx is done or
+// its deadline has passed. It returns the best sequence found so far, which
+// may be nil, and whether the search was exhaustive.
+func BestSolutionContext(ctx context.Context, tree *Tree, setWeights []float32, maxDistance int, opts *SolutionOpts) ([]*Tree, bool) {
+	return bestSolution(ctx, tree, opts.loadWeights(tree, setWeights), maxDistance, opts)
+}
+
+// bestSolution is BestSolutionContext for the weight of the bar and plates
+// only.
+func bestSolution(ctx context.Context, tree *Tree, setWeights []float32, maxDistance int, opts *SolutionOpts) ([]*Tree, bool) {
 	if len(setWeights) == 0 {
-		return nil
+		return nil, true
 	}
 
+	cancelled := cancelFunc(ctx)
+
 	bestScore := math.MaxInt32
+	bestPlates := math.MaxInt32
 	var solution []*Tree
 
 	foundSolution := func(score int, nodes []*Tree) {
-		if score < bestScore {
+		plates := 0
+		for _, n := range nodes {
+			plates += n.Depth
+		}
+		if score < bestScore || (score == bestScore && plates < bestPlates) {
 			bestScore = score
+			bestPlates = plates
 			solution = nodes
 			if opts.Debug {
-				for _, n := range nodes {
-					fmt.Printf("%3v: %v (score=%v)\n", n.TotalWeight(), n, n.Score(opts.PreferLessPlates))
-				}
-				fmt.Printf("total=%v\n\n", score)
+				debugSolution(nodes, score, opts)
 			}
 		}
 	}
@@ -65,6 +129,9 @@ func BestSolution(tree *Tree, setWeights []float32, maxDistance int, opts *Solut
 		weight := tail[i]
 		oldNextFn := nextFn
 		nextFn = func(prevScore int, prevNodes []*Tree) {
+			if cancelled() {
+				return
+			}
 			prevNode := prevNodes[len(prevNodes)-1]
 			prevNode.WalkNearby(maxDistance, func(node *Tree, dist int) {
 				if node.TotalWeight() == weight {
@@ -85,30 +152,116 @@ func BestSolution(tree *Tree, setWeights []float32, maxDistance int, opts *Solut
 	}
 
 	tree.Walk(func(node *Tree) {
+		if cancelled() {
+			return
+		}
 		if node.TotalWeight() == head {
 			nodes := []*Tree{node}
 			nextFn(node.Score(opts.PreferLessPlates), nodes)
 		}
 	})
 
-	return solution
+	return solution, !cancelled()
+}
+
+// debugMu keeps the debug output of solvers running concurrently from
+// interleaving.
+var debugMu sync.Mutex
+
+// debugSolution prints the nodes of a solution and its score to stderr, away
+// from output such as a CSV plan on stdout.
+func debugSolution(nodes []*Tree, score int, opts *SolutionOpts) {
+	var b strings.Builder
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "%3v: %v (score=%v)\n", n.TotalWeight(), n, n.Score(opts.PreferLessPlates))
+	}
+	fmt.Fprintf(&b, "total=%v\n\n", score)
+
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// cancelFunc returns a function which reports whether ctx is done. The
+// deadline of ctx is checked directly since timers may not fire while the
+// search is running in a single threaded environment such as WebAssembly.
+func cancelFunc(ctx context.Context) func() bool {
+	deadline, hasDeadline := ctx.Deadline()
+	done := false
+	steps := 0
+	return func() bool {
+		if done {
+			return true
+		}
+		// check before counting the step so a context which is already done
+		// stops the search on the first step rather than after an interval
+		if steps%cancelCheckInterval == 0 {
+			done = ctx.Err() != nil || (hasDeadline && time.Now().After(deadline))
+		}
+		steps++
+		return done
+	}
+}
+
+// SolutionScore returns the combined score of a sequence of nodes as
+// calculated by BestSolution.
+func SolutionScore(nodes []*Tree, opts *SolutionOpts) int {
+	score := 0
+	for i, node := range nodes {
+		if i == 0 {
+			score = node.Score(opts.PreferLessPlates)
+		} else {
+			score += node.Score(opts.PreferLessPlates) * nodes[i-1].Distance(node)
+		}
+	}
+	return score
 }
 
 // SimpleSolution returns the best plate arrangement for each individual weight
 // in setWeights.
 func SimpleSolution(tree *Tree, setWeights []float32, opts *SolutionOpts) []*Tree {
+	solution, _ := SimpleSolutionContext(context.Background(), tree, setWeights, opts)
+	return solution
+}
+
+// SimpleSolutionContext is SimpleSolution but stops searching once ctx is
+// done or its deadline has passed. It returns nil if an arrangement was not
+// found for every weight, and whether the search was exhaustive.
+func SimpleSolutionContext(ctx context.Context, tree *Tree, setWeights []float32, opts *SolutionOpts) ([]*Tree, bool) {
 	solution := make([]*Tree, 0)
+	exhaustive := true
 
-	for _, weight := range setWeights {
-		best := BestSolution(tree, []float32{weight}, 0, opts)
+	for _, weight := range opts.loadWeights(tree, setWeights) {
+		best, ok := bestSolution(ctx, tree, []float32{weight}, 0, opts)
+		exhaustive = exhaustive && ok
 		if best == nil {
-			return nil
+			return nil, exhaustive
 		}
 		solution = append(solution, best[0])
 	}
 
-	return solution
+	return solution, exhaustive
+}
+
+// NearestSolution returns the loading for weight within maxDistance plate
+// changes of node with the lowest score, as calculated by BestSolution, or nil
+// if there is none. Collars are included in weight according to opts.
+func NearestSolution(node *Tree, weight float32, maxDistance int, opts *SolutionOpts) *Tree {
+	weight -= opts.collarWeight(node)
 
+	var best *Tree
+	bestScore := math.MaxInt32
+	node.WalkNearby(maxDistance, func(n *Tree, dist int) {
+		if n.TotalWeight() != weight {
+			return
+		}
+		score := n.Score(opts.PreferLessPlates) * dist
+		if score < bestScore || (score == bestScore && n.Depth < best.Depth) {
+			best = n
+			bestScore = score
+		}
+	})
+	return best
 }
 
 func RoundUpToNearest(n float32, inc int) int {
@@ -128,3 +281,29 @@ func FloorLimit(n int, limit int) int {
 	}
 	return n
 }
+
+// ReverseSolution returns the node for plates loaded on each side of the bar
+// and the total weight on the bar, including collars. Plates which are not in
+// tree are loaded on a new bar of the same weight so tree is not modified.
+func ReverseSolution(tree *Tree, plates []float32, opts *SolutionOpts) (*Tree, float32) {
+	node := tree
+	if len(plates) > 0 {
+		node = tree.Find(plates...)
+		if node == nil {
+			node = NewImplementTree(tree.Value, tree.Sleeves).Add(plates...)
+		}
+	}
+	return node, node.TotalWeight() + opts.collarWeight(tree)
+}
+
+const kgPerLb = 0.45359237
+
+// LbToKg converts pounds to kilograms.
+func LbToKg(n float32) float32 {
+	return n * kgPerLb
+}
+
+// KgToLb converts kilograms to pounds.
+func KgToLb(n float32) float32 {
+	return n / kgPerLb
+}
@@ -0,0 +1,139 @@
+package platecalc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bruteForceScore returns the lowest combined score of every sequence of
+// nodes which load setWeights with consecutive nodes within maxDistance of
+// each other, using the same scoring as BestSolution. Returns false if there
+// is no such sequence.
+func bruteForceScore(tree *Tree, setWeights []float32, maxDistance int, opts *SolutionOpts) (int, bool) {
+	candidates := make([][]*Tree, len(setWeights))
+	tree.Walk(func(node *Tree) {
+		for i, weight := range setWeights {
+			if node.TotalWeight()+opts.Collars(node, i) == weight {
+				candidates[i] = append(candidates[i], node)
+			}
+		}
+	})
+
+	best := math.MaxInt32
+	var search func(i int, prev *Tree, score int)
+	search = func(i int, prev *Tree, score int) {
+		if i == len(setWeights) {
+			if score < best {
+				best = score
+			}
+			return
+		}
+		for _, node := range candidates[i] {
+			if prev == nil {
+				search(i+1, node, node.Score(opts.PreferLessPlates))
+				continue
+			}
+			dist := prev.Distance(node)
+			if dist <= maxDistance {
+				search(i+1, node, score+node.Score(opts.PreferLessPlates)*dist)
+			}
+		}
+	}
+	search(0, nil, 0)
+
+	return best, best != math.MaxInt32
+}
+
+// randomCase returns a small random inventory tree and a sequence of
+// reachable set weights.
+func randomCase(r *rand.Rand) (*Tree, []float32, *SolutionOpts) {
+	denominations := []float32{45, 35, 25, 10, 5, 2.5}
+
+	plates := make([]float32, 1+r.Intn(5))
+	for i := range plates {
+		plates[i] = denominations[r.Intn(len(denominations))]
+	}
+
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	opts := &SolutionOpts{
+		PreferLessPlates: r.Intn(2) == 0,
+		CollarWeight:     []float32{0, 2.5}[r.Intn(2)],
+		WarmupSets:       r.Intn(3),
+		WarmupCollars:    r.Intn(2) == 0,
+	}
+
+	nodes := make([]*Tree, 0)
+	tree.Walk(func(node *Tree) {
+		nodes = append(nodes, node)
+	})
+
+	setWeights := make([]float32, 1+r.Intn(4))
+	for i := range setWeights {
+		setWeights[i] = nodes[r.Intn(len(nodes))].TotalWeight() + opts.Collars(tree, i)
+	}
+
+	return tree, setWeights, opts
+}
+
+func assertLoads(t *testing.T, setWeights []float32, solution []*Tree, opts *SolutionOpts) {
+	if assert.Len(t, solution, len(setWeights)) {
+		for i, node := range solution {
+			assert.Equal(t, setWeights[i], node.TotalWeight()+opts.Collars(node, i))
+		}
+	}
+}
+
+func TestBestSolutionOptimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		tree, setWeights, opts := randomCase(r)
+		maxDistance := 1 + r.Intn(6)
+
+		t.Run(fmt.Sprintf("%v/%v/%v", i, setWeights, maxDistance), func(t *testing.T) {
+			want, ok := bruteForceScore(tree, setWeights, maxDistance, opts)
+			solution := BestSolution(tree, setWeights, maxDistance, opts)
+			if !ok {
+				assert.Nil(t, solution)
+				return
+			}
+
+			assertLoads(t, setWeights, solution, opts)
+			assert.Equal(t, want, SolutionScore(solution, opts))
+			for j := 1; j < len(solution); j++ {
+				assert.LessOrEqual(t, solution[j-1].Distance(solution[j]), maxDistance)
+			}
+		})
+	}
+}
+
+func TestSimpleSolutionNotBetter(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		tree, setWeights, opts := randomCase(r)
+
+		t.Run(fmt.Sprintf("%v/%v", i, setWeights), func(t *testing.T) {
+			simple := SimpleSolution(tree, setWeights, opts)
+			assertLoads(t, setWeights, simple, opts)
+
+			// search far enough that the simple solution is a candidate
+			maxDistance := 0
+			for j := 1; j < len(simple); j++ {
+				if dist := simple[j-1].Distance(simple[j]); dist > maxDistance {
+					maxDistance = dist
+				}
+			}
+
+			best := BestSolution(tree, setWeights, maxDistance, opts)
+			assertLoads(t, setWeights, best, opts)
+			assert.LessOrEqual(t, SolutionScore(best, opts), SolutionScore(simple, opts))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package platecalc
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -35,31 +36,150 @@ func TestBestSolution(t *testing.T) {
 		tree.Add(p...)
 	}
 
-	sets := []int{55, 65, 75, 55}
-	result := BestSolution(tree, sets, 5, false)
+	sets := []float32{55, 65, 75, 55}
+	result := BestSolution(tree, sets, 5, &SolutionOpts{})
 
 	got := make([]string, 0)
 	for _, node := range result {
 		got = append(got, node.String())
 	}
 
+	// Each set after the first costs the score of its loading times the
+	// distance from the previous set: 10*2 + 20*1 + 5*3 = 60 for this
+	// solution, less than 15*1 + 25*2 + 5*1 = 75 for 5; 5, 5; 5, 10; 5 even
+	// though it changes more plates.
 	want := []string{
 		"5",
-		"5, 5",
-		"5, 10",
+		"10",
+		"10, 5",
 		"5",
 	}
 	assert.Equal(t, want, got)
 }
 
+func TestBestSolutionContext(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	sets := []float32{55, 65, 75, 55}
+	result, exhaustive := BestSolutionContext(context.Background(), tree, sets, 5, &SolutionOpts{})
+	assert.True(t, exhaustive)
+	assert.Equal(t, BestSolution(tree, sets, 5, &SolutionOpts{}), result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, exhaustive = BestSolutionContext(ctx, tree, sets, 5, &SolutionOpts{})
+	assert.False(t, exhaustive)
+}
+
+func TestCancelFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := cancelFunc(ctx)
+	assert.False(t, cancelled())
+
+	// cancellation is noticed at the next check interval
+	cancel()
+	for i := 1; i < cancelCheckInterval; i++ {
+		assert.False(t, cancelled())
+	}
+	assert.True(t, cancelled())
+
+	// a context which is already done is noticed on the first step
+	assert.True(t, cancelFunc(ctx)())
+}
+
+func TestBestSolutionStable(t *testing.T) {
+	sets := []float32{55, 65, 75, 85, 55}
+	solve := func(plates ...float32) []string {
+		tree := NewTree(nil, 45)
+		for _, p := range Permutations(plates...) {
+			tree.Add(p...)
+		}
+		got := make([]string, 0)
+		for _, node := range BestSolution(tree, sets, 5, &SolutionOpts{PreferLessPlates: true}) {
+			got = append(got, node.String())
+		}
+		return got
+	}
+
+	want := solve(5, 5, 10, 10, 2.5)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, want, solve(2.5, 10, 5, 10, 5))
+	}
+}
+
+func TestSolutionScore(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	sets := []float32{55, 65, 75, 55}
+	opts := &SolutionOpts{}
+	best := SolutionScore(BestSolution(tree, sets, 5, opts), opts)
+	simple := SolutionScore(SimpleSolution(tree, sets, opts), opts)
+
+	assert.Equal(t, 60, best)
+	assert.LessOrEqual(t, best, simple)
+}
+
+func TestBestSolutionCollars(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	sets := []float32{55, 70, 80}
+	opts := &SolutionOpts{
+		CollarWeight: 2.5,
+		WarmupSets:   1,
+	}
+	result := BestSolution(tree, sets, 5, opts)
+
+	got := make([]float32, 0)
+	for i, node := range result {
+		got = append(got, node.TotalWeight()+opts.Collars(tree, i))
+	}
+	assert.Equal(t, sets, got)
+	assert.Equal(t, "5", result[0].String())
+}
+
+func TestBestSolutionAsymmetric(t *testing.T) {
+	plates := []float32{10, 5, 2.5}
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(plates...) {
+		tree.Add(p...)
+	}
+
+	sets := []float32{55, 57.5, 60}
+	assert.Nil(t, BestSolution(tree, sets, 5, &SolutionOpts{}))
+
+	tree.AddSingles(plates, 2.5)
+	result := BestSolution(tree, sets, 5, &SolutionOpts{})
+
+	got := make([]string, 0)
+	for _, node := range result {
+		got = append(got, node.String())
+	}
+
+	want := []string{
+		"5",
+		"left: 5, 2.5; right: 5",
+		"5, 2.5",
+	}
+	assert.Equal(t, want, got)
+}
+
 func TestSimpleSolution(t *testing.T) {
 	tree := NewTree(nil, 45)
 	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
 		tree.Add(p...)
 	}
 
-	sets := []int{55, 65, 75, 55}
-	result := SimpleSolution(tree, sets, false)
+	sets := []float32{55, 65, 75, 55}
+	result := SimpleSolution(tree, sets, &SolutionOpts{})
 
 	got := make([]string, 0)
 	for _, node := range result {
@@ -75,6 +195,39 @@ func TestSimpleSolution(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestReverseSolution(t *testing.T) {
+	tree := NewTree(nil, 45)
+	tree.Add(45, 25)
+
+	node, total := ReverseSolution(tree, []float32{45, 25}, &SolutionOpts{})
+	assert.Equal(t, tree.Find(45, 25), node)
+	assert.Equal(t, float32(185), total)
+
+	// plates outside of the inventory should not modify the tree
+	node, total = ReverseSolution(tree, []float32{45, 10}, &SolutionOpts{CollarWeight: 2.5})
+	assert.Equal(t, "45, 10", node.String())
+	assert.Equal(t, float32(160), total)
+	assert.Nil(t, tree.Find(45, 10))
+
+	node, total = ReverseSolution(tree, nil, &SolutionOpts{})
+	assert.Equal(t, tree, node)
+	assert.Equal(t, float32(45), total)
+}
+
+func TestNearestSolution(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(10, 5, 2.5) {
+		tree.Add(p...)
+	}
+	opts := &SolutionOpts{}
+
+	assert.Equal(t, tree.Find(10), NearestSolution(tree, 65, 5, opts))
+	assert.Equal(t, tree.Find(5, 2.5), NearestSolution(tree.Find(5), 60, 5, opts))
+	assert.Equal(t, tree.Find(5), NearestSolution(tree.Find(5), 55, 5, opts))
+	assert.Equal(t, tree.Find(5, 2.5), NearestSolution(tree.Find(5), 65, 5, &SolutionOpts{CollarWeight: 2.5}))
+	assert.Nil(t, NearestSolution(tree.Find(10, 5, 2.5), 45, 2, opts))
+}
+
 func TestRoundUpToNearest(t *testing.T) {
 	tests := []struct {
 		n    int
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var threshold = flag.Float64("threshold", 10, "percent increase in ns/op or allocs/op reported as a regression")
+
+// result is the average of every run of one benchmark.
+type result struct {
+	nsPerOp     float64
+	allocsPerOp float64
+	runs        int
+}
+
+func main() {
+	flag.Usage = func() {
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage: benchcmp [baseline] [results]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	baseline, err := parseFile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	results, err := parseFile(flag.Arg(1))
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	names := make([]string, 0, len(baseline))
+	for name := range baseline {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	regressions := 0
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "name\told ns/op\tnew ns/op\tdelta\told allocs/op\tnew allocs/op\tdelta\t\n")
+	for _, name := range names {
+		old := baseline[name]
+		cur, ok := results[name]
+		if !ok {
+			fmt.Fprintf(w, "%v\t%.0f\t-\t\t%.0f\t-\t\tmissing\n", name, old.nsPerOp, old.allocsPerOp)
+			continue
+		}
+
+		nsDelta := delta(old.nsPerOp, cur.nsPerOp)
+		allocsDelta := delta(old.allocsPerOp, cur.allocsPerOp)
+
+		status := ""
+		if nsDelta > *threshold || allocsDelta > *threshold {
+			status = "REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(w, "%v\t%.0f\t%.0f\t%+.1f%%\t%.0f\t%.0f\t%+.1f%%\t%v\n",
+			name, old.nsPerOp, cur.nsPerOp, nsDelta, old.allocsPerOp, cur.allocsPerOp, allocsDelta, status)
+	}
+	w.Flush()
+
+	if regressions > 0 {
+		fmt.Printf("\n%v regression(s) over %v%%\n", regressions, *threshold)
+		os.Exit(1)
+	}
+}
+
+// delta returns the percent change from old to cur.
+func delta(old, cur float64) float64 {
+	if old == 0 {
+		return 0
+	}
+	return (cur - old) / old * 100
+}
+
+// parseFile returns the results of every benchmark in the output of
+// `go test -bench`, keyed by benchmark name without the GOMAXPROCS suffix.
+func parseFile(path string) (map[string]*result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	results := make(map[string]*result)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := trimProcs(fields[0])
+		r, ok := results[name]
+		if !ok {
+			r = &result{}
+			results[name] = r
+		}
+
+		// fields after the iteration count are value and unit pairs
+		for i := 2; i+1 < len(fields); i += 2 {
+			n, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				r.nsPerOp = (r.nsPerOp*float64(r.runs) + n) / float64(r.runs+1)
+			case "allocs/op":
+				r.allocsPerOp = (r.allocsPerOp*float64(r.runs) + n) / float64(r.runs+1)
+			}
+		}
+		r.runs++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// trimProcs removes the GOMAXPROCS suffix from a benchmark name.
+// Ex: BenchmarkBestSolution/lb8/fsl-4 -> BenchmarkBestSolution/lb8/fsl
+func trimProcs(name string) string {
+	i := strings.LastIndex(name, "-")
+	if i < 0 || strings.LastIndex(name, "/") > i {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kdeloach/platecalc"
+	"github.com/kdeloach/platecalc/plans"
+	"gopkg.in/yaml.v3"
+)
+
+// maxScoredPlates limits effort scoring to inventories whose permutation tree
+// can be built in a reasonable amount of time.
+const maxScoredPlates = 9
+
+type filesFlag []string
+
+func (f *filesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *filesFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+var barWeight = flag.Int("bar", 45, "bar weight")
+var platesFlag = flag.String("plates", "45,35,25,10,5,2.5", "plate denominations which may be purchased")
+var maxWeight = flag.Float64("max", 0, "heaviest total weight which must be reachable")
+var increment = flag.Float64("inc", 5, "required increment between weights")
+var maxPlates = flag.Int("count", 0, "maximum number of pairs of plates (default 16)")
+var maxWeightPerSide = flag.Float64("weight", 0, "maximum total weight of the plates on each side")
+var collarWeight = flag.Float64("collar", 0, "weight of each collar when checking reachable weights")
+var maxDistance = flag.Int("maxdistance", 5, "maximum distance to search tree")
+var files filesFlag
+
+func main() {
+	flag.Var(&files, "file", "workout plan settings file used to score plate changes (repeatable)")
+	flag.Usage = func() {
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage: buy -max [weight]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *maxWeight == 0 {
+		log.Fatalf("-max is required")
+	}
+
+	denominations, err := plans.ParsePlates(*platesFlag)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	settings := make([]*plans.WorkoutPlanSettings, 0)
+	for _, file := range files {
+		s, err := readSettings(file)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		settings = append(settings, s)
+	}
+
+	inv := &platecalc.InventoryOpts{
+		Bar:              float32(*barWeight),
+		Denominations:    denominations,
+		MaxWeight:        float32(*maxWeight),
+		Increment:        float32(*increment),
+		MaxPlates:        *maxPlates,
+		MaxWeightPerSide: float32(*maxWeightPerSide),
+	}
+	opts := &platecalc.SolutionOpts{
+		CollarWeight: float32(*collarWeight),
+	}
+
+	candidates := platecalc.OptimizeInventory(inv, opts)
+	if candidates == nil {
+		log.Fatalf("no inventory found")
+	}
+
+	efforts := make([]int, len(candidates))
+	if len(settings) > 0 {
+		if len(candidates[0]) > maxScoredPlates {
+			fmt.Fprintf(os.Stderr, "warning: too many plates to score plate changes\n")
+		} else {
+			scored := make([][]float32, 0, len(candidates))
+			efforts = make([]int, 0, len(candidates))
+			for _, plates := range candidates {
+				e, err := effort(plates, settings)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "skipping %v: %v\n", formatPlates(plates), err)
+					continue
+				}
+				scored = append(scored, plates)
+				efforts = append(efforts, e)
+			}
+			if len(scored) == 0 {
+				log.Fatalf("no inventory can load every plan")
+			}
+			candidates = scored
+			sort.Stable(byEffort{candidates, efforts})
+		}
+	}
+
+	for i, plates := range candidates {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("plates: %v\n", formatPlates(plates))
+		fmt.Printf("pairs:  %v\n", formatPairs(plates))
+		if efforts[i] > 0 {
+			fmt.Printf("effort: %v\n", efforts[i])
+		}
+	}
+}
+
+// effort returns the combined score of every plate change needed to load
+// each plan in settings with plates, or an error if plates can't load a plan.
+// Each plan is solved with the options of its profile, as by cmd/plan.
+func effort(plates []float32, settings []*plans.WorkoutPlanSettings) (int, error) {
+	// profiles with the same imbalance share a tree
+	trees := make(map[float32]*platecalc.Tree)
+
+	// PlateCalcFn is called from each of the plan's workers
+	var mu sync.Mutex
+	total := 0
+	for _, s := range settings {
+		tree, ok := trees[s.MaxImbalance]
+		if !ok {
+			tree = platecalc.NewTree(nil, float32(*barWeight))
+			for _, perm := range platecalc.Permutations(plates...) {
+				tree.Add(perm...)
+			}
+			if s.MaxImbalance > 0 {
+				tree.AddSingles(plates, s.MaxImbalance)
+			}
+			trees[s.MaxImbalance] = tree
+		}
+		planOpts := &platecalc.SolutionOpts{
+			PreferLessPlates: s.PreferLessPlates,
+			CollarWeight:     s.CollarWeight,
+			WarmupSets:       s.WarmupSets,
+			WarmupCollars:    s.WarmupCollars,
+		}
+		s.PlateCalcFn = func(lifts [][]int) [][]*platecalc.Tree {
+			weights := plans.LiftWeights(lifts)
+			solution := platecalc.SessionSolution(tree, weights, *maxDistance, planOpts)
+			if solution == nil {
+				// plate changes are too far apart for the search distance
+				solution = make([][]*platecalc.Tree, len(weights))
+				for i, sets := range weights {
+					if solution[i] = platecalc.SimpleSolution(tree, sets, planOpts); solution[i] == nil {
+						return nil
+					}
+				}
+			}
+			session := make([]*platecalc.Tree, 0)
+			for _, nodes := range solution {
+				session = append(session, nodes...)
+			}
+			mu.Lock()
+			total += platecalc.SolutionScore(session, planOpts)
+			mu.Unlock()
+			return solution
+		}
+
+		plan, err := plans.NewWorkoutPlan(s)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		if err := plan.Write(csv.NewWriter(ioutil.Discard)); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+type byEffort struct {
+	candidates [][]float32
+	efforts    []int
+}
+
+func (b byEffort) Len() int           { return len(b.candidates) }
+func (b byEffort) Less(i, j int) bool { return b.efforts[i] < b.efforts[j] }
+func (b byEffort) Swap(i, j int) {
+	b.candidates[i], b.candidates[j] = b.candidates[j], b.candidates[i]
+	b.efforts[i], b.efforts[j] = b.efforts[j], b.efforts[i]
+}
+
+func readSettings(file string) (*plans.WorkoutPlanSettings, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	settings := &plans.WorkoutPlanSettings{}
+	err = yaml.Unmarshal(buf, settings)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func formatPlates(plates []float32) string {
+	s := make([]string, len(plates))
+	for i, p := range plates {
+		s[i] = strconv.FormatFloat(float64(p), 'f', -1, 32)
+	}
+	return strings.Join(s, ",")
+}
+
+func formatPairs(plates []float32) string {
+	s := make([]string, 0)
+	for i := 0; i < len(plates); {
+		n := 1
+		for i+n < len(plates) && plates[i+n] == plates[i] {
+			n++
+		}
+		s = append(s, fmt.Sprintf("%vx%v", plates[i], n))
+		i += n
+	}
+	return strings.Join(s, " ")
+}
@@ -1,107 +1,138 @@
+//go:build js && wasm
+// +build js,wasm
+
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
+	"strings"
 	"syscall/js"
+	"time"
 
-	"github.com/kdeloach/platecalc"
+	"github.com/kdeloach/platecalc/api"
 )
 
-func calcWrapper() js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		barWeight := 45
-		less := false
-		simple := false
-		maxDistance := 5
-		plates := []float32{45, 35, 25, 10, 10, 5, 5, 2.5}
+// defaultTimeout is the longest a search may run unless the request sets
+// timeoutMs. Unlike the server, the browser may search for as long as it
+// likes.
+const defaultTimeout = 5 * time.Second
 
-		// Parse arguments
-		setWeights := []int{}
-		for _, arg := range args {
-			if arg.Type() == js.TypeNumber {
-				setWeights = append(setWeights, arg.Int())
-			} else if arg.Type() == js.TypeObject {
-				if v, err := tryGetBool(arg, "ordered"); err == nil {
-					simple = v
-				}
-				if v, err := tryGetBool(arg, "less"); err == nil {
-					less = v
-				}
-				if v, err := tryGetInt(arg, "barWeight"); err == nil {
-					barWeight = v
-				}
-				if v, err := tryGetFloatArray(arg, "plates"); err == nil {
-					plates = v
-				}
-			}
-		}
+// handler runs a request decoded from JSON, searching for at most timeout.
+type handler func(req string, timeout time.Duration) (interface{}, error)
 
-		tree := platecalc.NewTree(nil, float32(barWeight))
-		for _, perm := range platecalc.Permutations(plates...) {
-			tree.Add(perm...)
-		}
-
-		opts := &platecalc.SolutionOpts{
-			PreferLessPlates: less,
-		}
-
-		var solution []*platecalc.Tree
-		if simple {
-			solution = platecalc.SimpleSolution(tree, setWeights, opts)
-		} else {
-			solution = platecalc.BestSolution(tree, setWeights, maxDistance, opts)
-		}
-
-		if solution == nil {
-			return map[string]interface{}{
-				"error": "no solution",
-			}
+// promise returns a JS function which takes a request object and returns a
+// Promise. The Promise resolves with the result as a plain object, or rejects
+// with an Error whose code is "invalid_request", "no_solution" or "internal".
+func promise(fn handler) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		req := "{}"
+		if len(args) > 0 && args[0].Type() == js.TypeObject {
+			req = js.Global().Get("JSON").Call("stringify", args[0]).String()
+		} else if len(args) > 0 && args[0].Type() != js.TypeUndefined {
+			return reject(&api.RequestError{Msg: "request must be an object"})
 		}
 
-		result := map[string]interface{}{}
-		for i, node := range solution {
-			k := fmt.Sprintf("set %d (%d)", i+1, node.TotalWeight())
-			result[k] = node.String()
-		}
-		return result
+		var executor js.Func
+		executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			resolve, reject := args[0], args[1]
+			executor.Release()
+			// Settle the promise from a goroutine so the callback returns
+			// first. WebAssembly is single threaded, so the search still
+			// blocks the page until it finishes; load the module in a Web
+			// Worker to keep a page responsive.
+			go func() {
+				resp, err := run(fn, req)
+				if err != nil {
+					reject.Invoke(jsError(err))
+					return
+				}
+				resolve.Invoke(resp)
+			}()
+			return nil
+		})
+		return js.Global().Get("Promise").New(executor)
 	})
 }
 
-func tryGetBool(obj js.Value, key string) (v bool, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New("key not defined in object")
-		}
-	}()
-	return obj.Get(key).Bool(), nil
+func run(fn handler, req string) (js.Value, error) {
+	resp, err := fn(req, timeout(req))
+	if err != nil {
+		return js.Undefined(), err
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return js.Global().Get("JSON").Call("parse", string(b)), nil
 }
 
-func tryGetInt(obj js.Value, key string) (v int, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New("key not defined in object")
-		}
-	}()
-	return obj.Get(key).Int(), nil
+// timeout returns the timeoutMs field of req, or defaultTimeout if it isn't
+// set.
+func timeout(req string) time.Duration {
+	var opts api.SearchOptions
+	json.Unmarshal([]byte(req), &opts)
+	if opts.TimeoutMs > 0 {
+		return time.Duration(opts.TimeoutMs) * time.Millisecond
+	}
+	return defaultTimeout
 }
 
-func tryGetFloatArray(obj js.Value, key string) (ret []float32, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New("key not defined in object")
-		}
-	}()
-	arr := obj.Get(key)
-	ret = []float32{}
-	for i := 0; i < arr.Length(); i++ {
-		n := float32(arr.Index(i).Float())
-		ret = append(ret, n)
+// reject returns a Promise rejected with err.
+func reject(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", jsError(err))
+}
+
+func jsError(err error) js.Value {
+	code := "internal"
+	var reqErr *api.RequestError
+	var solveErr *api.NoSolutionError
+	switch {
+	case errors.As(err, &reqErr):
+		code = "invalid_request"
+	case errors.As(err, &solveErr):
+		code = "no_solution"
 	}
-	return
+	e := js.Global().Get("Error").New(err.Error())
+	e.Set("code", code)
+	return e
+}
+
+func decode(req string, v interface{}) error {
+	return api.Decode(strings.NewReader(req), v)
 }
 
 func main() {
-	js.Global().Set("platecalc", calcWrapper())
+	js.Global().Set("platecalc", map[string]interface{}{
+		"calc": promise(func(body string, timeout time.Duration) (interface{}, error) {
+			req := &api.CalcRequest{}
+			if err := decode(body, req); err != nil {
+				return nil, err
+			}
+			return api.Calc(context.Background(), req, timeout)
+		}),
+		"reverse": promise(func(body string, timeout time.Duration) (interface{}, error) {
+			req := &api.ReverseRequest{}
+			if err := decode(body, req); err != nil {
+				return nil, err
+			}
+			return api.Reverse(req)
+		}),
+		"reachable": promise(func(body string, timeout time.Duration) (interface{}, error) {
+			req := &api.ReachableRequest{}
+			if err := decode(body, req); err != nil {
+				return nil, err
+			}
+			return api.Reachable(req)
+		}),
+		"plan": promise(func(body string, timeout time.Duration) (interface{}, error) {
+			req := &api.PlanRequest{}
+			if err := decode(body, req); err != nil {
+				return nil, err
+			}
+			return api.Plan(context.Background(), req, timeout)
+		}),
+	})
 	<-make(chan bool)
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// errInterrupted is returned by readLine when ctrl-c is pressed.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the commands of the interactive prompt.
+type lineReader interface {
+	// readLine prints prompt and returns the next line, which may be
+	// recalled from history.
+	readLine(prompt string, history []string) (string, error)
+}
+
+// newLineReader returns a line editor if in is a terminal, or a reader of
+// plain lines otherwise.
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return &termReader{fd: int(f.Fd()), in: bufio.NewReader(f), out: out}
+	}
+	return &scanReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s *scanReader) readLine(prompt string, history []string) (string, error) {
+	fmt.Fprint(s.out, prompt)
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return s.scanner.Text(), nil
+}
+
+// termReader edits lines on a terminal. The terminal is only in raw mode
+// while a line is read, so output in between is written as usual.
+type termReader struct {
+	fd  int
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (t *termReader) readLine(prompt string, history []string) (string, error) {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(t.fd, state)
+	return editLine(t.in, t.out, prompt, history)
+}
+
+// editLine reads a line from in, a terminal in raw mode, echoing it to out.
+// The left and right arrows, home, end, ctrl-a and ctrl-e move the cursor,
+// and the up and down arrows recall lines from history.
+func editLine(in *bufio.Reader, out io.Writer, prompt string, history []string) (string, error) {
+	var line []rune
+	pos := 0
+	// hist is the index in history of the recalled line; draft keeps the
+	// line being typed while older lines are recalled
+	hist := len(history)
+	var draft []rune
+
+	recall := func(i int) {
+		if hist == len(history) {
+			draft = line
+		}
+		hist = i
+		if hist == len(history) {
+			line = draft
+		} else {
+			line = []rune(history[hist])
+		}
+		pos = len(line)
+	}
+
+	redraw := func() {
+		fmt.Fprintf(out, "\r%s%s\x1b[K", prompt, string(line))
+		if n := len(line) - pos; n > 0 {
+			fmt.Fprintf(out, "\x1b[%dD", n)
+		}
+	}
+
+	fmt.Fprint(out, prompt)
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(out, "\r\n")
+			return string(line), nil
+		case 3: // ctrl-c
+			fmt.Fprint(out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // ctrl-d
+			if len(line) == 0 {
+				fmt.Fprint(out, "\r\n")
+				return "", io.EOF
+			}
+		case 1: // ctrl-a
+			pos = 0
+		case 5: // ctrl-e
+			pos = len(line)
+		case 8, 127: // backspace
+			if pos > 0 {
+				line = append(line[:pos-1:pos-1], line[pos:]...)
+				pos--
+			}
+		case 27:
+			switch key := escapeKey(in); key {
+			case "A": // up
+				if hist > 0 {
+					recall(hist - 1)
+				}
+			case "B": // down
+				if hist < len(history) {
+					recall(hist + 1)
+				}
+			case "C": // right
+				if pos < len(line) {
+					pos++
+				}
+			case "D": // left
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~":
+				pos = 0
+			case "F", "4~":
+				pos = len(line)
+			case "3~": // delete
+				if pos < len(line) {
+					line = append(line[:pos:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if r < ' ' {
+				continue
+			}
+			line = append(line[:pos:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+		redraw()
+	}
+}
+
+// escapeKey reads the rest of an escape sequence, such as "[A" or "OA" for
+// the up arrow, and returns its final part, "A".
+func escapeKey(in *bufio.Reader) string {
+	r, _, err := in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return ""
+	}
+	var key strings.Builder
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		key.WriteRune(r)
+		if r >= '@' && r <= '~' {
+			return key.String()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditLine(t *testing.T) {
+	history := []string{"add 95", "seq 95 135"}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "add 135\r", "add 135"},
+		{"backspace", "add 1355\x7f\r", "add 135"},
+		{"left and insert", "ad 135\x1b[D\x1b[D\x1b[D\x1b[Dd\r", "add 135"},
+		{"home and end", "dd 13\x01a\x055\r", "add 135"},
+		{"delete", "aadd 135\x01\x1b[3~\r", "add 135"},
+		{"up", "\x1b[A\r", "seq 95 135"},
+		{"up twice", "\x1b[A\x1b[A\r", "add 95"},
+		{"up past the first", "\x1b[A\x1b[A\x1b[A\r", "add 95"},
+		{"up and edit", "\x1b[A\x1b[A\x7f\x7f135\r", "add 135"},
+		{"down to draft", "add\x1b[A\x1b[B 135\r", "add 135"},
+		{"application mode arrows", "\x1bOA\r", "seq 95 135"},
+		{"multibyte", "add 1é\x7f35\r", "add 135"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := editLine(bufio.NewReader(strings.NewReader(tt.input)), ioutil.Discard, "> ", history)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, line)
+		})
+	}
+
+	_, err := editLine(bufio.NewReader(strings.NewReader("\x04")), ioutil.Discard, "> ", history)
+	assert.Equal(t, io.EOF, err)
+	_, err = editLine(bufio.NewReader(strings.NewReader("add\x03")), ioutil.Discard, "> ", history)
+	assert.Equal(t, errInterrupted, err)
+}
@@ -1,74 +1,234 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/kdeloach/platecalc"
+	"github.com/kdeloach/platecalc/render"
 )
 
-var barWeight = flag.Int("bar", 45, "bar weight")
+var barWeight = flag.Float64("bar", 0, "bar or handle weight (default weight of -implement, in kilograms with -kg)")
+var implement = flag.String("implement", "barbell", "implement type: barbell, dumbbell, landmine or pin")
 var platesFlag = flag.String("plates", "45,35,25,10,10,5,5,2.5,1.25", "available plates")
 var maxDistance = flag.Int("maxdistance", 5, "maximum distance to search tree")
-var debug = flag.Bool("debug", false, "display debug output")
+var debug = flag.Bool("debug", false, "display debug output on stderr")
 var simple = flag.Bool("simple", false, "use simple plate orderings")
 var preferLess = flag.Bool("less", false, "prefer less/heavier plates")
+var collarWeight = flag.Float64("collar", 0, "weight of each collar")
+var warmupSets = flag.Int("warmups", 0, "number of leading warm-up sets")
+var warmupCollars = flag.Bool("warmupcollars", false, "use collars on warm-up sets")
+var reverse = flag.String("reverse", "", "calculate total weight of plates loaded on each side")
+var kg = flag.Bool("kg", false, "weights are in kilograms")
+var imbalance = flag.Float64("imbalance", 0, "largest plate which may be loaded on one side of the bar only (0 for none)")
+var timeout = flag.Duration("timeout", 0, "maximum time to search for a solution (0 for no limit)")
+var drawStyle = flag.String("draw", "", "draw the bar for each set: ascii or unicode")
+var color = flag.Bool("color", false, "color plates in drawings by IWF plate color")
+var interactive = flag.Bool("i", false, "interactive mode")
+var lifters liftersFlag
+
+type liftersFlag [][]float32
+
+func (f *liftersFlag) String() string {
+	return fmt.Sprintf("%v", *f)
+}
+
+func (f *liftersFlag) Set(s string) error {
+	weights, err := parsePlates(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, weights)
+	return nil
+}
 
 func main() {
+	flag.Var(&lifters, "lifter", "weights for one lifter sharing the bar, taking turns with other lifters (repeatable)")
 	flag.Usage = func() {
 		w := flag.CommandLine.Output()
 		fmt.Fprintf(w, "Usage: calc [weight:int]+\n")
+		fmt.Fprintf(w, "       calc -lifter [weight,...] -lifter [weight,...]\n")
+		fmt.Fprintf(w, "       calc -reverse [plate,...]\n")
+		fmt.Fprintf(w, "       calc -i\n")
 		flag.PrintDefaults()
 	}
 
 	flag.Parse()
 
-	plates, err := parsePlates()
+	impl, err := platecalc.FindImplement(*implement)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	bar := impl.Weight
+	if *kg {
+		bar = impl.KgWeight
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "bar" {
+			bar = float32(*barWeight)
+		}
+	})
+
+	opts := &platecalc.SolutionOpts{
+		Debug:            *debug,
+		PreferLessPlates: *preferLess,
+		CollarWeight:     float32(*collarWeight),
+		WarmupSets:       *warmupSets,
+		WarmupCollars:    *warmupCollars,
+	}
+
+	if *reverse != "" {
+		loaded, err := parsePlates(*reverse)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		node, total := platecalc.ReverseSolution(platecalc.NewImplementTree(bar, impl.Sleeves), loaded, opts)
+		fmt.Printf("%v: %v\n", formatWeight(total), node)
+		draw(node, opts)
+		return
+	}
+
+	plates, err := parsePlates(*platesFlag)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
 
+	if *interactive {
+		histFile := ""
+		if home, err := os.UserHomeDir(); err == nil {
+			histFile = filepath.Join(home, ".platecalc_history")
+		}
+		newREPL(bar, impl.Sleeves, plates, opts, histFile, os.Stdout).run(os.Stdin)
+		return
+	}
+
 	setWeights, err := parseWeights()
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
 
-	if len(setWeights) == 0 {
+	if len(setWeights) == 0 && len(lifters) == 0 {
 		log.Fatalf("one or more weights is required")
 	}
 
-	tree := platecalc.NewTree(nil, float32(*barWeight))
-	for _, perm := range platecalc.Permutations(plates...) {
-		tree.Add(perm...)
-	}
+	tree := buildTree(bar, impl.Sleeves, plates)
 
-	opts := &platecalc.SolutionOpts{
-		Debug:            *debug,
-		PreferLessPlates: *preferLess,
+	ctx, cancel := solverContext()
+	defer cancel()
+
+	if len(lifters) > 0 {
+		solvePartners(ctx, tree, opts)
+		return
 	}
 
 	var solution []*platecalc.Tree
+	var exhaustive bool
 	if *simple {
-		solution = platecalc.SimpleSolution(tree, setWeights, opts)
+		solution, exhaustive = platecalc.SimpleSolutionContext(ctx, tree, setWeights, opts)
 	} else {
-		solution = platecalc.BestSolution(tree, setWeights, *maxDistance, opts)
+		solution, exhaustive = platecalc.BestSolutionContext(ctx, tree, setWeights, *maxDistance, opts)
 	}
 	if solution == nil {
+		if !exhaustive {
+			log.Fatalf("no solution found before timeout")
+		}
 		log.Fatalf("no solution found")
 		return
 	}
+	if !exhaustive {
+		fmt.Fprintf(os.Stderr, "warning: search timed out; solution may not be optimal\n")
+	}
 
-	for _, node := range solution {
-		fmt.Printf("%3v: %v\n", node.TotalWeight(), node)
+	transitions := platecalc.Transitions(solution)
+	for i, node := range solution {
+		fmt.Printf("%3v: %v (%v)\n", node.TotalWeight()+opts.Collars(tree, i), node, transitions[i])
+		draw(node, opts)
+	}
+}
+
+// solvePartners prints the plates for each set taken by lifters sharing the
+// bar, in the order the sets are taken.
+func solvePartners(ctx context.Context, tree *platecalc.Tree, opts *platecalc.SolutionOpts) {
+	solution, exhaustive := platecalc.PartnerSolutionContext(ctx, tree, lifters, *maxDistance, opts)
+	if solution == nil {
+		if !exhaustive {
+			log.Fatalf("no solution found before timeout")
+		}
+		log.Fatalf("no solution found")
 	}
+	if !exhaustive {
+		fmt.Fprintf(os.Stderr, "warning: search timed out; solution may not be optimal\n")
+	}
+
+	var prev *platecalc.Tree
+	for _, turn := range platecalc.PartnerTurns(lifters) {
+		node := solution[turn.Lifter][turn.Set]
+		name := string(rune('A' + turn.Lifter))
+		fmt.Printf("%v%v %3v: %v (%v)\n", name, turn.Set+1, node.TotalWeight()+opts.Collars(tree, turn.Set), node, platecalc.NewTransition(prev, node))
+		prev = node
+		draw(node, opts)
+	}
+}
+
+// draw prints a drawing of node if -draw is set.
+func draw(node *platecalc.Tree, opts *platecalc.SolutionOpts) {
+	if *drawStyle == "" {
+		return
+	}
+	style, err := render.ParseStyle(*drawStyle)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	fmt.Printf("%v\n\n", render.Text(node, &render.TextOpts{
+		Style:   style,
+		Color:   *color,
+		Kg:      *kg,
+		Collars: opts.CollarWeight > 0,
+	}))
+}
+
+// buildTree returns the tree of every permutation of plates on a bar.
+func buildTree(bar float32, sleeves int, plates []float32) *platecalc.Tree {
+	tree := platecalc.NewImplementTree(bar, sleeves)
+	for _, perm := range platecalc.Permutations(plates...) {
+		tree.Add(perm...)
+	}
+	if *imbalance > 0 {
+		tree.AddSingles(plates, float32(*imbalance))
+	}
+	return tree
+}
+
+func solverContext() (context.Context, context.CancelFunc) {
+	if *timeout > 0 {
+		return context.WithTimeout(context.Background(), *timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// formatWeight returns total in both pounds and kilograms.
+func formatWeight(total float32) string {
+	lb, kilos := total, platecalc.LbToKg(total)
+	if *kg {
+		lb, kilos = platecalc.KgToLb(total), total
+	}
+	return fmt.Sprintf("%v lb / %v kg", round(lb), round(kilos))
+}
+
+func round(n float32) float32 {
+	return float32(math.Round(float64(n)*100) / 100)
 }
 
-func parsePlates() ([]float32, error) {
+func parsePlates(strPlates string) ([]float32, error) {
 	plates := []float32{}
-	for _, s := range strings.Split(*platesFlag, ",") {
+	for _, s := range strings.Split(strPlates, ",") {
 		n, err := strconv.ParseFloat(s, 32)
 		if err != nil {
 			return nil, err
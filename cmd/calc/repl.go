@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kdeloach/platecalc"
+)
+
+const replHelp = `Commands:
+  add [weight]            load the bar for weight with the fewest changes
+  seq [weight]+           plan a sequence of sets starting from the empty bar
+  next                    load the next set in the sequence (or press enter)
+  bar [weight]            change the bar weight
+  plates [plate[xN],...]  change the available plates, N per side (ex: 45x2,25)
+  undo                    undo the last command
+  history                 list previous commands
+  !!, ![n]                repeat the last command or command n
+  help                    show this message
+  quit                    exit
+`
+
+// replState is everything undo restores.
+type replState struct {
+	bar     float32
+	plates  []float32
+	tree    *platecalc.Tree
+	node    *platecalc.Tree   // Current loading
+	queue   []*platecalc.Tree // Remaining sets planned with seq
+	set     int               // Index of queue[0] in the planned sequence
+	collars float32           // Weight of the collars on the current loading
+}
+
+// repl is the interactive prompt started with -i. The tree is kept in memory
+// between commands and only rebuilt when the bar or plates change.
+type repl struct {
+	replState
+	sleeves  int
+	opts     *platecalc.SolutionOpts
+	undo     []replState
+	history  []string
+	histFile string
+	out      io.Writer
+}
+
+// newREPL returns a prompt which saves commands to histFile, and loads the
+// commands saved there before, unless histFile is empty.
+func newREPL(bar float32, sleeves int, plates []float32, opts *platecalc.SolutionOpts, histFile string, out io.Writer) *repl {
+	r := &repl{sleeves: sleeves, opts: opts, histFile: histFile, out: out}
+	r.bar = bar
+	r.plates = plates
+	r.tree = buildTree(bar, sleeves, plates)
+	r.node = r.tree
+	r.collars = r.fullCollars()
+	if histFile != "" {
+		r.loadHistory()
+	}
+	return r
+}
+
+// run reads commands from in until quit or the end of the input. Lines are
+// edited with the arrow keys and history if in is a terminal.
+func (r *repl) run(in io.Reader) {
+	fmt.Fprint(r.out, replHelp)
+	r.show()
+
+	lines := newLineReader(in, r.out)
+	for {
+		text, err := lines.readLine("> ", r.history)
+		if err != nil {
+			if err != errInterrupted {
+				fmt.Fprintln(r.out)
+			}
+			return
+		}
+		line := strings.TrimSpace(text)
+
+		if strings.HasPrefix(line, "!") {
+			recalled, err := r.recall(line)
+			if err != nil {
+				fmt.Fprintf(r.out, "error: %v\n", err)
+				continue
+			}
+			line = recalled
+			fmt.Fprintln(r.out, line)
+		}
+		if line == "quit" || line == "exit" {
+			return
+		}
+		if line != "" && line != "history" {
+			r.addHistory(line)
+		}
+		if err := r.exec(line); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		}
+	}
+}
+
+func (r *repl) exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		// enter loads the next set, if there is one
+		if len(r.queue) == 0 {
+			return nil
+		}
+		fields = []string{"next"}
+	}
+	cmd, args := fields[0], fields[1:]
+
+	switch cmd {
+	case "help":
+		fmt.Fprint(r.out, replHelp)
+		return nil
+	case "history":
+		for i, h := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, h)
+		}
+		return nil
+	case "undo":
+		if len(r.undo) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		r.replState = r.undo[len(r.undo)-1]
+		r.undo = r.undo[:len(r.undo)-1]
+		r.show()
+		return nil
+	}
+
+	prev := r.replState
+	var err error
+	switch cmd {
+	case "add":
+		err = r.add(args)
+	case "seq":
+		err = r.seq(args)
+	case "next":
+		err = r.next()
+	case "bar":
+		err = r.setBar(args)
+	case "plates":
+		err = r.setPlates(args)
+	default:
+		err = fmt.Errorf("unknown command: %s (try help)", cmd)
+	}
+	if err != nil {
+		r.replState = prev
+		return err
+	}
+	r.undo = append(r.undo, prev)
+	r.show()
+	return nil
+}
+
+func (r *repl) add(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: add [weight]")
+	}
+	weight, err := strconv.ParseFloat(args[0], 32)
+	if err != nil {
+		return err
+	}
+	node := platecalc.NearestSolution(r.node, float32(weight), *maxDistance, r.opts)
+	if node == nil {
+		// start over from the simplest loading
+		solution := platecalc.SimpleSolution(r.tree, []float32{float32(weight)}, r.opts)
+		if solution == nil {
+			return fmt.Errorf("no solution found for %v", weight)
+		}
+		node = solution[0]
+	}
+	r.printChange(r.node, node)
+	r.node, r.collars = node, r.fullCollars()
+	return nil
+}
+
+func (r *repl) seq(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: seq [weight]+")
+	}
+	setWeights := make([]float32, len(args))
+	for i, s := range args {
+		n, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		setWeights[i] = float32(n)
+	}
+	ctx, cancel := solverContext()
+	defer cancel()
+	solution, exhaustive := platecalc.BestSolutionContext(ctx, r.tree, setWeights, *maxDistance, r.opts)
+	if solution == nil {
+		return fmt.Errorf("no solution found")
+	}
+	if !exhaustive {
+		fmt.Fprintf(r.out, "warning: search timed out; solution may not be optimal\n")
+	}
+	prev := r.node
+	for i, node := range solution {
+		fmt.Fprintf(r.out, "%3v: %v (%v)\n", node.TotalWeight()+r.opts.Collars(r.tree, i), node, platecalc.NewTransition(prev, node))
+		prev = node
+	}
+	r.queue, r.set = solution, 0
+	return nil
+}
+
+func (r *repl) next() error {
+	if len(r.queue) == 0 {
+		return fmt.Errorf("no sets left in the sequence")
+	}
+	r.printChange(r.node, r.queue[0])
+	r.node, r.queue = r.queue[0], r.queue[1:]
+	r.collars = r.opts.Collars(r.tree, r.set)
+	r.set++
+	return nil
+}
+
+func (r *repl) setBar(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bar [weight]")
+	}
+	n, err := strconv.ParseFloat(args[0], 32)
+	if err != nil {
+		return err
+	}
+	r.bar = float32(n)
+	r.rebuild()
+	return nil
+}
+
+func (r *repl) setPlates(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: plates [plate[xN],...]")
+	}
+	plates := []float32{}
+	for _, s := range strings.Split(args[0], ",") {
+		count := 1
+		if i := strings.Index(s, "x"); i >= 0 {
+			n, err := strconv.Atoi(s[i+1:])
+			if err != nil {
+				return err
+			}
+			s, count = s[:i], n
+		}
+		p, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			plates = append(plates, float32(p))
+		}
+	}
+	r.plates = plates
+	r.rebuild()
+	return nil
+}
+
+// rebuild builds a new tree for the bar and plates, keeping the current
+// loading if it can still be loaded. The planned sequence is cleared.
+func (r *repl) rebuild() {
+	tree := buildTree(r.bar, r.sleeves, r.plates)
+	node := tree
+	if r.node.Depth > 0 {
+		plates := r.node.Plates()
+		if n := len(plates); r.node.Symmetric() {
+			node = tree.Find(plates...)
+		} else if n == 1 {
+			node = tree.FindSingle(plates[0])
+		} else if parent := tree.Find(plates[:n-1]...); parent != nil {
+			node = parent.FindSingle(plates[n-1])
+		} else {
+			node = nil
+		}
+		if node == nil {
+			fmt.Fprintf(r.out, "current loading is not possible with these plates; starting from an empty bar\n")
+			node = tree
+		}
+	}
+	r.tree, r.node, r.queue = tree, node, nil
+}
+
+func (r *repl) show() {
+	fmt.Fprintf(r.out, "bar: %v; plates: %v\n", r.bar, r.plates)
+	if r.node.Depth == 0 {
+		fmt.Fprintf(r.out, "current %v: empty bar\n", r.node.TotalWeight()+r.collars)
+	} else {
+		fmt.Fprintf(r.out, "current %v: %v\n", r.node.TotalWeight()+r.collars, r.node)
+	}
+	if len(r.queue) > 0 {
+		node := r.queue[0]
+		fmt.Fprintf(r.out, "next    %v: %v (%v)\n", node.TotalWeight()+r.opts.Collars(r.tree, r.set), node, platecalc.NewTransition(r.node, node))
+	}
+}
+
+// fullCollars returns the weight of a collar on every sleeve.
+func (r *repl) fullCollars() float32 {
+	return r.opts.CollarWeight * float32(r.sleeves)
+}
+
+func (r *repl) printChange(from, to *platecalc.Tree) {
+	fmt.Fprintf(r.out, "%v\n", platecalc.NewTransition(from, to))
+}
+
+// recall returns the command in history for "!!" or "!n".
+func (r *repl) recall(line string) (string, error) {
+	if len(r.history) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+	if line == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", fmt.Errorf("no such command in history: %s", line)
+	}
+	return r.history[n-1], nil
+}
+
+func (r *repl) loadHistory() {
+	f, err := os.Open(r.histFile)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r.history = append(r.history, scanner.Text())
+	}
+}
+
+func (r *repl) addHistory(line string) {
+	r.history = append(r.history, line)
+	if r.histFile == "" {
+		return
+	}
+	f, err := os.OpenFile(r.histFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
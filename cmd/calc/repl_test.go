@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kdeloach/platecalc"
+)
+
+func TestREPL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "add",
+			input: "add 135\n",
+			want:  []string{"> add 45\n", "current 135: 45\n"},
+		},
+		{
+			name:  "seq and next",
+			input: "seq 95 135\nnext\n\n",
+			want: []string{
+				" 95: 25 (add 25)\n135: 45 (remove 25; add 45)\n",
+				"next    95: 25 (add 25)\n",
+				"> add 25\n",
+				"current 95: 25\nnext    135: 45 (remove 25; add 45)\n",
+				"> remove 25; add 45\n",
+				"current 135: 45\n",
+			},
+		},
+		{
+			name:  "enter without a sequence",
+			input: "\nnext\n",
+			want:  []string{"> > error: no sets left in the sequence\n"},
+		},
+		{
+			name:  "bar",
+			input: "add 135\nbar 35\n",
+			want:  []string{"bar: 35; plates: [45 25 10 5 2.5]\ncurrent 125: 45\n"},
+		},
+		{
+			name:  "plates",
+			input: "plates 45x2,25\n",
+			want:  []string{"bar: 45; plates: [45 45 25]\n"},
+		},
+		{
+			name:  "undo",
+			input: "add 135\nundo\nundo\n",
+			want: []string{
+				"current 135: 45\n> bar: 45; plates: [45 25 10 5 2.5]\ncurrent 45: empty bar\n",
+				"error: nothing to undo\n",
+			},
+		},
+		{
+			name:  "repeat last",
+			input: "add 95\n!!\nhistory\n",
+			want:  []string{"> add 95\n", "   1  add 95\n   2  add 95\n"},
+		},
+		{
+			name:  "repeat n",
+			input: "add 95\nadd 135\n!1\n!9\n",
+			want: []string{
+				"> add 95\nremove 45; add 25\n",
+				"error: no such command in history: !9\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := newREPL(45, 2, []float32{45, 25, 10, 5, 2.5}, &platecalc.SolutionOpts{}, "", &out)
+			r.run(strings.NewReader(tt.input))
+			for _, want := range tt.want {
+				assert.Contains(t, out.String(), want)
+			}
+		})
+	}
+}
+
+func TestREPLHistoryFile(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), "history")
+
+	var out bytes.Buffer
+	newREPL(45, 2, []float32{45, 25}, &platecalc.SolutionOpts{}, histFile, &out).run(strings.NewReader("add 95\nhistory\n"))
+	buf, err := ioutil.ReadFile(histFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "add 95\n", string(buf))
+
+	// the next prompt recalls commands from the last one
+	out.Reset()
+	newREPL(45, 2, []float32{45, 25}, &platecalc.SolutionOpts{}, histFile, &out).run(strings.NewReader("!1\n"))
+	assert.Contains(t, out.String(), "> add 95\nadd 25\n")
+}
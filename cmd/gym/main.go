@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/kdeloach/platecalc"
+	"github.com/kdeloach/platecalc/plans"
+	"gopkg.in/yaml.v3"
+)
+
+var file = flag.String("file", "", "gym settings file")
+var maxDistance = flag.Int("maxdistance", 5, "maximum distance to search tree")
+var preferLess = flag.Bool("less", false, "prefer less/heavier plates")
+
+type gymSettings struct {
+	Plates     string        `yaml:"Plates"`     // Every plate in the gym, one per pair
+	RackPlates string        `yaml:"RackPlates"` // Plates a single rack may use
+	Racks      []rackSetting `yaml:"Racks"`
+}
+
+type rackSetting struct {
+	Name   string    `yaml:"Name"`
+	Bar    float32   `yaml:"Bar"`
+	Plates string    `yaml:"Plates"`
+	Sets   []float32 `yaml:"Sets"`
+}
+
+func main() {
+	flag.Parse()
+
+	buf, err := ioutil.ReadFile(*file)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	settings := &gymSettings{
+		RackPlates: "45,35,25,10,10,5,5,2.5",
+	}
+	err = yaml.Unmarshal(buf, settings)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	inventory, err := plans.ParsePlates(settings.Plates)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	if len(settings.Racks) == 0 {
+		log.Fatalf("%v: no racks", *file)
+	}
+
+	// racks with the same bar and plates share a tree
+	trees := make(map[string]*platecalc.Tree)
+
+	racks := make([]*platecalc.Rack, len(settings.Racks))
+	for i, r := range settings.Racks {
+		if r.Bar == 0 {
+			r.Bar = 45
+		}
+		if r.Plates == "" {
+			r.Plates = settings.RackPlates
+		}
+
+		key := fmt.Sprintf("%v/%v", r.Bar, r.Plates)
+		tree, ok := trees[key]
+		if !ok {
+			plates, err := plans.ParsePlates(r.Plates)
+			if err != nil {
+				log.Fatalf(err.Error())
+			}
+			tree = platecalc.NewTree(nil, r.Bar)
+			for _, perm := range platecalc.Permutations(plates...) {
+				tree.Add(perm...)
+			}
+			trees[key] = tree
+		}
+
+		racks[i] = &platecalc.Rack{
+			Name:       r.Name,
+			Tree:       tree,
+			SetWeights: r.Sets,
+		}
+	}
+
+	opts := &platecalc.SolutionOpts{
+		PreferLessPlates: *preferLess,
+	}
+
+	alloc, err := platecalc.AllocatePlates(inventory, racks, *maxDistance, opts)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	if len(alloc.Loadings[0]) == 0 {
+		log.Fatalf("%v: no rack has any sets", *file)
+	}
+
+	for step := range alloc.Loadings[0] {
+		fmt.Printf("Step %v\n", step+1)
+		for i, rack := range racks {
+			node := alloc.Loadings[i][step]
+			if node != nil {
+				fmt.Printf("  %v %3v: %v\n", rack.Name, node.TotalWeight(), node)
+			}
+		}
+	}
+
+	if len(alloc.Conflicts) == 0 {
+		return
+	}
+
+	fmt.Printf("\nConflicts\n")
+	for _, c := range alloc.Conflicts {
+		rack := racks[c.Rack]
+		fmt.Printf("  Step %v %v %v: %v needs %v more\n",
+			c.Step+1, rack.Name, c.Loading.TotalWeight(), c.Loading, formatShortage(c.Shortage))
+		if len(c.Alternatives) == 0 {
+			fmt.Printf("    no alternative loading\n")
+			continue
+		}
+		fmt.Printf("    using: %v\n", c.Alternatives[0])
+		for _, alt := range c.Alternatives[1:] {
+			fmt.Printf("    or:    %v\n", alt)
+		}
+	}
+}
+
+func formatShortage(shortage map[float32]int) string {
+	plates := make([]float32, 0, len(shortage))
+	for p := range shortage {
+		plates = append(plates, p)
+	}
+	sort.Slice(plates, func(i, j int) bool { return plates[i] > plates[j] })
+
+	s := make([]string, len(plates))
+	for i, p := range plates {
+		s[i] = fmt.Sprintf("%vx%v", p, shortage[p])
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/kdeloach/platecalc/workoutlog"
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage: log <command> [flags]
+
+Commands:
+  record  add sets to a workout log
+  report  compare a workout log with a plan
+  cycle   adjust the training maxes in a profile from the logged AMRAP sets
+
+Run "log <command> -h" for the flags of each command.
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "record":
+		record(os.Args[2:])
+	case "report":
+		report(os.Args[2:])
+	case "cycle":
+		cycle(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func record(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	file := fs.String("file", "log.csv", "workout log; .jsonl files are JSON lines, anything else CSV")
+	date := fs.String("date", time.Now().Format(workoutlog.DateFormat), "date lifted")
+	week := fs.Int("week", 0, "plan week (0 if not part of the plan)")
+	day := fs.Int("day", 0, "plan day (0 if not part of the plan)")
+	lift := fs.String("lift", "", "lift name, as in the plan (Squat, Bench, Deadlift, Press)")
+	weight := fs.Float64("weight", 0, "weight lifted")
+	reps := fs.Int("reps", 0, "reps lifted")
+	sets := fs.Int("sets", 1, "number of sets with the same weight and reps")
+	rpe := fs.Float64("rpe", 0, "rating of perceived exertion (0 if not recorded)")
+	notes := fs.String("notes", "", "notes")
+	fs.Parse(args)
+
+	d, err := time.Parse(workoutlog.DateFormat, *date)
+	if err != nil {
+		log.Fatalf("invalid date: %v", *date)
+	}
+	if *lift == "" {
+		log.Fatalf("-lift is required")
+	}
+
+	entries := make([]workoutlog.Entry, *sets)
+	for i := range entries {
+		entries[i] = workoutlog.Entry{
+			Date:   d,
+			Week:   *week,
+			Day:    *day,
+			Lift:   *lift,
+			Weight: float32(*weight),
+			Reps:   *reps,
+			RPE:    float32(*rpe),
+			Notes:  *notes,
+		}
+	}
+	if err := workoutlog.Append(*file, entries...); err != nil {
+		log.Fatalf(err.Error())
+	}
+}
+
+func report(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	file := fs.String("file", "log.csv", "workout log")
+	planFile := fs.String("plan", "", "plan CSV written by the plan command")
+	since := fs.String("since", "", "only use sets lifted on or after this date, such as the start of the cycle")
+	fs.Parse(args)
+
+	entries := loadLog(*file, *since)
+	if *planFile == "" {
+		printPRs(workoutlog.PRs(entries))
+		return
+	}
+	plan := loadPlan(*planFile)
+
+	r := workoutlog.Compare(plan, entries)
+	fmt.Printf("Adherence: %v/%v sets (%.0f%%)\n", r.Completed, r.Planned, r.Adherence()*100)
+	if len(r.Missed) > 0 {
+		fmt.Println("\nMissed sets:")
+		for _, m := range r.Missed {
+			fmt.Printf("  %v\n", m)
+		}
+	}
+	if len(r.PRs) > 0 {
+		fmt.Println()
+		printPRs(r.PRs)
+	}
+}
+
+func printPRs(prs []workoutlog.PR) {
+	if len(prs) == 0 {
+		return
+	}
+	fmt.Println("PRs:")
+	for _, pr := range prs {
+		fmt.Printf("  %v\n", pr)
+	}
+}
+
+var lifts = []string{"Squat", "Bench", "Deadlift", "Press"}
+
+// profile is the part of a plan profile which sets the training maxes.
+type profile struct {
+	SquatRepMax        int              `yaml:"SquatRepMax"`
+	DeadliftRepMax     int              `yaml:"DeadliftRepMax"`
+	PressRepMax        int              `yaml:"PressRepMax"`
+	BenchRepMax        int              `yaml:"BenchRepMax"`
+	TrainingMaxPercent int              `yaml:"TrainingMaxPercent"`
+	TrainingMaxRules   workoutlog.Rules `yaml:"TrainingMaxRules"`
+}
+
+func (p *profile) repMax(lift string) int {
+	switch lift {
+	case "Squat":
+		return p.SquatRepMax
+	case "Deadlift":
+		return p.DeadliftRepMax
+	case "Press":
+		return p.PressRepMax
+	}
+	return p.BenchRepMax
+}
+
+func cycle(args []string) {
+	fs := flag.NewFlagSet("cycle", flag.ExitOnError)
+	file := fs.String("file", "log.csv", "workout log")
+	planFile := fs.String("plan", "", "plan CSV of the cycle, written by the plan command")
+	profileFile := fs.String("profile", "", "workout plan settings file to adjust")
+	since := fs.String("since", "", "only use sets lifted on or after this date, such as the start of the cycle")
+	write := fs.Bool("write", false, "save the new rep maxes to the profile")
+	fs.Parse(args)
+
+	if *planFile == "" || *profileFile == "" {
+		log.Fatalf("-plan and -profile are required")
+	}
+	entries := loadLog(*file, *since)
+	plan := loadPlan(*planFile)
+
+	buf, err := ioutil.ReadFile(*profileFile)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	p := &profile{}
+	if err := yaml.Unmarshal(buf, p); err != nil {
+		log.Fatalf("%v: %v", *profileFile, err)
+	}
+	if p.TrainingMaxPercent <= 0 {
+		log.Fatalf("%v: TrainingMaxPercent is required", *profileFile)
+	}
+	tmPerc := float32(p.TrainingMaxPercent) / 100
+
+	// The profile sets rep maxes, and the training max is a percent of the
+	// rep max, so the new training max is saved as the rep max it comes from.
+	results := workoutlog.AMRAPResults(plan, entries)
+	repMaxes := make(map[string]int)
+	for _, lift := range lifts {
+		repMax := p.repMax(lift)
+		if repMax == 0 {
+			continue
+		}
+		a := p.TrainingMaxRules.Adjust(lift, float32(repMax)*tmPerc, results[lift])
+		repMaxes[lift+"RepMax"] = int(math.Round(float64(a.NewTrainingMax / tmPerc)))
+		fmt.Printf("%v: %v training max %v -> %v, %vRepMax %v -> %v (%v)\n",
+			a.Lift, a.Action, a.TrainingMax, a.NewTrainingMax, lift, repMax, repMaxes[lift+"RepMax"], a.Reason)
+	}
+
+	if *write {
+		if err := updateProfile(*profileFile, buf, repMaxes); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+}
+
+// updateProfile sets the values of top-level keys in the YAML profile at
+// path. Lines are replaced in place so the rest of the file, including
+// comments, is unchanged.
+func updateProfile(path string, buf []byte, values map[string]int) error {
+	for key, v := range values {
+		re := regexp.MustCompile(`(?m)^(` + regexp.QuoteMeta(key) + `:[ \t]*)\d+`)
+		if !re.Match(buf) {
+			return fmt.Errorf("%v: %v not found", path, key)
+		}
+		buf = re.ReplaceAll(buf, []byte("${1}"+strconv.Itoa(v)))
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
+
+func loadLog(path, since string) []workoutlog.Entry {
+	entries, err := workoutlog.Load(path)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if since == "" {
+		return entries
+	}
+	start, err := time.Parse(workoutlog.DateFormat, since)
+	if err != nil {
+		log.Fatalf("invalid date: %v", since)
+	}
+	filtered := make([]workoutlog.Entry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Date.Before(start) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func loadPlan(path string) []workoutlog.PlannedSet {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	defer f.Close()
+	plan, err := workoutlog.ReadPlan(f)
+	if err != nil {
+		log.Fatalf("%v: %v", path, err)
+	}
+	return plan
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"html/template"
+	"io"
+
+	"github.com/kdeloach/platecalc/plans"
+)
+
+var htmlTemplate = template.Must(template.New("plan").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #bdbdbd; padding: 4px 8px; text-align: left; vertical-align: middle; }
+th { background: #eeeeee; }
+td svg { display: block; }
+@media print {
+  body { margin: 0; }
+  thead { display: table-header-group; }
+  tr { page-break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .Columns}}<td>{{.}}</td>{{end}}<td>{{.Diagram}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+type htmlRow struct {
+	Columns []string
+	Diagram template.HTML
+}
+
+// writeHTML writes plan as a printable HTML table. The last column of the
+// plan must be the Diagram column drawn as SVG.
+func writeHTML(w io.Writer, title string, plan plans.WorkoutPlan) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := plan.Write(cw); err != nil {
+		return err
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Title  string
+		Header []string
+		Rows   []htmlRow
+	}{Title: title}
+	if len(records) > 0 {
+		data.Header = records[0]
+		for _, record := range records[1:] {
+			n := len(record) - 1
+			data.Rows = append(data.Rows, htmlRow{
+				Columns: record[:n],
+				// SVG is generated by the render package, not user input
+				Diagram: template.HTML(record[n]),
+			})
+		}
+	}
+	return htmlTemplate.Execute(w, data)
+}
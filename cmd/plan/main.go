@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"io/ioutil"
 	"log"
 	"os"
+	"runtime"
 
 	"github.com/kdeloach/platecalc"
 	"github.com/kdeloach/platecalc/plans"
+	"github.com/kdeloach/platecalc/render"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,7 +19,12 @@ var barWeight = flag.Int("bar", 45, "bar weight")
 var file = flag.String("file", "", "workout plan settings file")
 var maxDistance = flag.Int("maxdistance", 5, "maximum distance to search tree")
 var delim = flag.String("delim", ",", "output delimiter")
-var debug = flag.Bool("debug", false, "display debug output")
+var debug = flag.Bool("debug", false, "display debug output on stderr")
+var cacheFile = flag.String("cache", "", "file to load and save solutions between runs")
+var workers = flag.Int("workers", runtime.NumCPU(), "number of lifts to solve concurrently")
+var timeout = flag.Duration("timeout", 0, "maximum time to search for each solution (0 for no limit)")
+var html = flag.Bool("html", false, "write a printable HTML plan with a diagram of the plate changes for each set")
+var drawStyle = flag.String("draw", "", "add a column drawing the bar for each set: ascii or unicode")
 
 func main() {
 	flag.Parse()
@@ -41,28 +49,85 @@ func main() {
 	for _, perm := range platecalc.Permutations(plates...) {
 		tree.Add(perm...)
 	}
+	if settings.MaxImbalance > 0 {
+		tree.AddSingles(plates, settings.MaxImbalance)
+	}
 
 	opts := &platecalc.SolutionOpts{
 		Debug:            *debug,
 		PreferLessPlates: settings.PreferLessPlates,
+		CollarWeight:     settings.CollarWeight,
+		WarmupSets:       settings.WarmupSets,
+		WarmupCollars:    settings.WarmupCollars,
 	}
 
-	settings.PlateCalcFn = func(setWeights []int) []*platecalc.Tree {
-		return platecalc.BestSolution(tree, setWeights, *maxDistance, opts)
+	cache := platecalc.NewSolutionCache()
+	if *cacheFile != "" {
+		if err := cache.Load(*cacheFile); err != nil {
+			log.Fatalf(err.Error())
+		}
 	}
 
-	var plan plans.WorkoutPlan
-	if settings.Plan == "Wendler531BBB" {
-		plan = plans.NewWendler531BBB(settings)
-	} else if settings.Plan == "Custom531" {
-		plan = plans.NewCustom531(settings)
-	} else if settings.Plan == "Stronglifts" {
-		plan = plans.NewStrongliftsPlan(settings)
+	settings.PlateCalcFn = func(lifts [][]int) [][]*platecalc.Tree {
+		ctx, cancel := solverContext()
+		defer cancel()
+
+		solution, exhaustive := cache.SessionSolutionContext(ctx, tree, plates, plans.LiftWeights(lifts), *maxDistance, opts)
+		if !exhaustive {
+			log.Printf("warning: search timed out for setWeights=%v", lifts)
+		}
+		return solution
+	}
+
+	settings.Workers = *workers
+
+	if *html {
+		svgOpts := &render.SVGOpts{Collars: settings.CollarWeight > 0, Width: 240}
+		settings.DrawFn = func(prev, plates *platecalc.Tree) string {
+			return render.DiffSVG(prev, plates, svgOpts)
+		}
+	} else if *drawStyle != "" {
+		style, err := render.ParseStyle(*drawStyle)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		drawOpts := &render.TextOpts{Style: style, Collars: settings.CollarWeight > 0}
+		settings.DrawFn = func(prev, plates *platecalc.Tree) string {
+			return render.Line(plates, drawOpts)
+		}
+	}
+
+	plan, err := plans.NewWorkoutPlan(settings)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	if *html {
+		if err := writeHTML(os.Stdout, settings.Plan, plan); err != nil {
+			log.Fatalf(err.Error())
+		}
 	} else {
-		log.Fatalf("unknown plan: %s\n", settings.Plan)
+		w := csv.NewWriter(os.Stdout)
+		w.Comma = []rune(*delim)[0]
+		if err := plan.Write(w); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
+	if *debug {
+		log.Printf("cache: hits=%v misses=%v hit rate=%.1f%%", cache.Hits, cache.Misses, cache.HitRate())
 	}
 
-	w := csv.NewWriter(os.Stdout)
-	w.Comma = []rune(*delim)[0]
-	plan.Write(w)
+	if *cacheFile != "" {
+		if err := cache.Save(*cacheFile); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+}
+
+func solverContext() (context.Context, context.CancelFunc) {
+	if *timeout > 0 {
+		return context.WithTimeout(context.Background(), *timeout)
+	}
+	return context.WithCancel(context.Background())
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/kdeloach/platecalc/api"
+)
+
+var addr = flag.String("addr", "localhost:8080", "address to listen on")
+var maxTimeout = flag.Duration("timeout", 10*time.Second, "maximum time to search for a solution")
+var maxRequests = flag.Int("requests", 0, "maximum requests to handle at once, or 0 for the number of CPUs")
+
+// slots holds a value for each request being handled. Requests beyond
+// -requests are turned away rather than queued behind searches which may run
+// for the whole timeout.
+var slots chan struct{}
+
+//go:embed openapi.json
+var openAPI []byte
+
+func main() {
+	flag.Parse()
+	if *maxRequests < 0 {
+		log.Fatal("-requests must not be negative")
+	}
+	if *maxRequests == 0 {
+		*maxRequests = runtime.NumCPU()
+	}
+	slots = make(chan struct{}, *maxRequests)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calc", post(func(ctx context.Context, body []byte) (interface{}, error) {
+		req := &api.CalcRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return api.Calc(ctx, req, *maxTimeout)
+	}))
+	mux.HandleFunc("/reverse", post(func(ctx context.Context, body []byte) (interface{}, error) {
+		req := &api.ReverseRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return api.Reverse(req)
+	}))
+	mux.HandleFunc("/reachable", post(func(ctx context.Context, body []byte) (interface{}, error) {
+		req := &api.ReachableRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return api.Reachable(req)
+	}))
+	mux.HandleFunc("/plan", post(func(ctx context.Context, body []byte) (interface{}, error) {
+		req := &api.PlanRequest{}
+		if err := decode(body, req); err != nil {
+			return nil, err
+		}
+		return api.Plan(ctx, req, *maxTimeout)
+	}))
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+
+	server := &http.Server{
+		Addr:         *addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: *maxTimeout + 10*time.Second,
+	}
+	log.Printf("listening on %v", *addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+func decode(body []byte, v interface{}) error {
+	return api.Decode(bytes.NewReader(body), v)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// post returns a handler for POST requests which writes the result of fn as
+// JSON, or an error with a status code for the kind of error. The solver is
+// stopped if the client disconnects. Status 503 is returned while -requests
+// requests are already being handled.
+func post(fn func(ctx context.Context, body []byte) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, &errorResponse{"method not allowed"})
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusServiceUnavailable, &errorResponse{"server busy"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, api.MaxBodyBytes))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, &errorResponse{err.Error()})
+			return
+		}
+
+		resp, err := fn(r.Context(), body)
+		var reqErr *api.RequestError
+		var solveErr *api.NoSolutionError
+		switch {
+		case errors.As(err, &reqErr):
+			writeJSON(w, http.StatusBadRequest, &errorResponse{err.Error()})
+		case errors.As(err, &solveErr):
+			writeJSON(w, http.StatusUnprocessableEntity, &errorResponse{err.Error()})
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, &errorResponse{err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, resp)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/kdeloach/platecalc"
+)
+
+var barWeight = flag.Int("bar", 45, "bar weight")
+var platesFlag = flag.String("plates", "45,35,25,10,10,5,5,2.5,1.25", "available plates")
+var minWeight = flag.Float64("min", 0, "minimum total weight (default bar weight)")
+var maxWeight = flag.Float64("max", 0, "maximum total weight (default all plates)")
+var increment = flag.Float64("inc", 0, "report missing weights in this increment (default 5 or 2.5 with -kg)")
+var kg = flag.Bool("kg", false, "weights are in kilograms")
+var preferLess = flag.Bool("less", false, "prefer less/heavier plates")
+var collarWeight = flag.Float64("collar", 0, "weight of each collar")
+
+func main() {
+	flag.Usage = func() {
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage: weights\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	plates, err := parsePlates()
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	opts := &platecalc.SolutionOpts{
+		PreferLessPlates: *preferLess,
+		CollarWeight:     float32(*collarWeight),
+	}
+
+	min := float32(*minWeight)
+	if min == 0 {
+		min = float32(*barWeight) + opts.CollarWeight*2
+	}
+
+	max := float32(*maxWeight)
+	if max == 0 {
+		max = float32(*barWeight) + opts.CollarWeight*2
+		for _, p := range plates {
+			max += p * 2
+		}
+	}
+
+	inc := float32(*increment)
+	if inc == 0 {
+		inc = 5
+		if *kg {
+			inc = 2.5
+		}
+	}
+
+	tree := platecalc.NewTree(nil, float32(*barWeight))
+	solution := platecalc.ReachableWeights(tree, plates, min, max, opts)
+
+	weights := make([]float32, 0, len(solution))
+	for _, node := range solution {
+		total := node.TotalWeight() + opts.CollarWeight*2
+		weights = append(weights, total)
+		fmt.Printf("%3v: %v\n", total, node)
+	}
+
+	missing := platecalc.MissingWeights(weights, min, max, inc)
+	if len(missing) > 0 {
+		fmt.Printf("\nmissing:\n")
+		for _, w := range missing {
+			fmt.Printf("%3v\n", w)
+		}
+	}
+}
+
+func parsePlates() ([]float32, error) {
+	plates := []float32{}
+	for _, s := range strings.Split(*platesFlag, ",") {
+		n, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, err
+		}
+		plates = append(plates, float32(n))
+	}
+	return plates, nil
+}
@@ -3,6 +3,7 @@ module github.com/kdeloach/platecalc
 go 1.16
 
 require (
-	github.com/stretchr/testify v1.7.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/term v0.0.0-20210503060354-a79de5458b56
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,44 @@
+package platecalc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Implement is a bar or handle which plates are loaded on.
+type Implement struct {
+	Name     string
+	Weight   float32 // Default weight of the empty bar or handle
+	KgWeight float32 // Default weight in kilograms
+	Sleeves  int     // Number of sleeves plates are loaded on
+}
+
+// Implements lists every supported implement. Dumbbells are loaded one at a
+// time, so set weights are for a single dumbbell. Landmines and loading pins
+// have a single sleeve; their default weight of zero counts the plates only.
+var Implements = []Implement{
+	{Name: "barbell", Weight: 45, KgWeight: 20, Sleeves: 2},
+	{Name: "dumbbell", Weight: 5, KgWeight: 2, Sleeves: 2},
+	{Name: "landmine", Weight: 0, KgWeight: 0, Sleeves: 1},
+	{Name: "pin", Weight: 0, KgWeight: 0, Sleeves: 1},
+}
+
+// FindImplement returns the implement in Implements with name.
+func FindImplement(name string) (Implement, error) {
+	names := make([]string, len(Implements))
+	for i, impl := range Implements {
+		if impl.Name == name {
+			return impl, nil
+		}
+		names[i] = impl.Name
+	}
+	return Implement{}, fmt.Errorf("unknown implement: %s (expected one of %s)", name, strings.Join(names, ", "))
+}
+
+// NewImplementTree returns the root of a tree for a bar or handle weighing
+// weight with plates loaded on the given number of sleeves.
+func NewImplementTree(weight float32, sleeves int) *Tree {
+	tree := NewTree(nil, weight)
+	tree.Sleeves = sleeves
+	return tree
+}
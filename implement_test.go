@@ -0,0 +1,62 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImplement(t *testing.T) {
+	impl, err := FindImplement("landmine")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, impl.Sleeves)
+
+	_, err = FindImplement("kettlebell")
+	assert.Error(t, err)
+}
+
+func TestReverseSolutionKg(t *testing.T) {
+	impl, err := FindImplement("barbell")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(20), impl.KgWeight)
+
+	node, total := ReverseSolution(NewImplementTree(impl.KgWeight, impl.Sleeves), []float32{25, 10}, &SolutionOpts{CollarWeight: 2.5})
+	assert.Equal(t, "25, 10", node.String())
+	assert.Equal(t, float32(95), total)
+	assert.InDelta(t, 209.44, KgToLb(total), 0.01)
+}
+
+func TestImplementTree(t *testing.T) {
+	tree := NewImplementTree(0, 1)
+	for _, p := range Permutations(25, 10, 5) {
+		tree.Add(p...)
+	}
+	assert.Equal(t, float32(35), tree.Find(25, 10).TotalWeight())
+
+	// Plates on a single sleeve can't be loaded unevenly
+	tree.AddSingles([]float32{25, 10, 5}, 5)
+	assert.Nil(t, tree.FindSingle(5))
+
+	opts := &SolutionOpts{CollarWeight: 1}
+	got := []string{}
+	for _, node := range BestSolution(tree, []float32{26, 36, 41}, 5, opts) {
+		got = append(got, node.String())
+	}
+	assert.Equal(t, []string{"25", "25, 10", "25, 10, 5"}, got)
+
+	node, total := ReverseSolution(NewImplementTree(0, 1), []float32{25, 10}, opts)
+	assert.Equal(t, 1, node.Sleeves)
+	assert.Equal(t, float32(36), total)
+}
+
+func TestImplementTreeDumbbell(t *testing.T) {
+	tree := NewImplementTree(5, 2)
+	for _, p := range Permutations(10, 5, 2.5) {
+		tree.Add(p...)
+	}
+	got := []string{}
+	for _, node := range BestSolution(tree, []float32{25, 30, 35}, 5, &SolutionOpts{}) {
+		got = append(got, node.String())
+	}
+	assert.Equal(t, []string{"10", "10, 2.5", "10, 5"}, got)
+}
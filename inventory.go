@@ -0,0 +1,89 @@
+package platecalc
+
+import (
+	"sort"
+)
+
+// defaultMaxPlates limits the search when InventoryOpts.MaxPlates is zero.
+const defaultMaxPlates = 16
+
+type InventoryOpts struct {
+	Bar              float32   // Bar weight
+	Denominations    []float32 // Plates which may be purchased
+	MaxWeight        float32   // Heaviest total weight which must be reachable
+	Increment        float32   // Required increment between the bar and MaxWeight
+	MaxPlates        int       // Maximum number of pairs of plates (zero for defaultMaxPlates)
+	MaxWeightPerSide float32   // Maximum total weight of one plate from each pair (zero for no limit)
+}
+
+// OptimizeInventory returns every smallest set of plates, one plate per pair,
+// which can load every weight from the bar to inv.MaxWeight in steps of
+// inv.Increment. Inventories are ordered from lightest to heaviest. Returns
+// nil if no inventory within the limits of inv can load every weight.
+func OptimizeInventory(inv *InventoryOpts, opts *SolutionOpts) [][]float32 {
+	min := inv.Bar + opts.collarWeight(NewTree(nil, inv.Bar))
+
+	denominations := append([]float32{}, inv.Denominations...)
+	sort.Slice(denominations, func(i, j int) bool { return denominations[i] > denominations[j] })
+
+	maxPlates := inv.MaxPlates
+	if maxPlates == 0 {
+		maxPlates = defaultMaxPlates
+	}
+
+	for n := 1; n <= maxPlates; n++ {
+		solution := make([][]float32, 0)
+
+		var search func(plates []float32, denominations []float32, weight float32)
+		search = func(plates []float32, denominations []float32, weight float32) {
+			if inv.MaxWeightPerSide > 0 && weight > inv.MaxWeightPerSide {
+				return
+			}
+			if len(plates) == n {
+				if canLoad(inv.Bar, plates, min, inv.MaxWeight, inv.Increment, opts) {
+					solution = append(solution, plates)
+				}
+				return
+			}
+			for i, p := range denominations {
+				next := append(plates[:len(plates):len(plates)], p)
+				search(next, denominations[i:], weight+p)
+			}
+		}
+		search(nil, denominations, 0)
+
+		if len(solution) > 0 {
+			sort.SliceStable(solution, func(i, j int) bool {
+				return sum(solution[i]) < sum(solution[j])
+			})
+			return solution
+		}
+	}
+
+	return nil
+}
+
+// canLoad returns true if every weight from min to max in steps of inc can be
+// loaded on a bar weighing bar using plates.
+func canLoad(bar float32, plates []float32, min, max, inc float32, opts *SolutionOpts) bool {
+	tree := NewTree(nil, bar)
+	collars := opts.collarWeight(tree)
+	if bar+collars+sum(plates)*2 < max {
+		return false
+	}
+
+	reachable := ReachableWeights(tree, plates, min, max, opts)
+	weights := make([]float32, len(reachable))
+	for i, node := range reachable {
+		weights[i] = node.TotalWeight() + collars
+	}
+	return len(MissingWeights(weights, min, max, inc)) == 0
+}
+
+func sum(plates []float32) float32 {
+	var total float32
+	for _, p := range plates {
+		total += p
+	}
+	return total
+}
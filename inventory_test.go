@@ -0,0 +1,49 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizeInventory(t *testing.T) {
+	inv := &InventoryOpts{
+		Bar:           45,
+		Denominations: []float32{45, 25, 10, 5, 2.5},
+		MaxWeight:     135,
+		Increment:     5,
+	}
+	got := OptimizeInventory(inv, &SolutionOpts{})
+	want := [][]float32{
+		{25, 10, 5, 5, 2.5},
+		{25, 10, 10, 5, 2.5},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestOptimizeInventoryLimits(t *testing.T) {
+	inv := &InventoryOpts{
+		Bar:           45,
+		Denominations: []float32{45, 10, 5},
+		MaxWeight:     135,
+		Increment:     5,
+		MaxPlates:     4,
+	}
+	assert.Nil(t, OptimizeInventory(inv, &SolutionOpts{}))
+}
+
+func TestOptimizeInventoryMaxWeightPerSide(t *testing.T) {
+	inv := &InventoryOpts{
+		Bar:           45,
+		Denominations: []float32{45, 25, 10, 5, 2.5},
+		MaxWeight:     135,
+		Increment:     5,
+	}
+	// the limit is on the plates of one side together, not on each plate
+	inv.MaxWeightPerSide = 47.5
+	assert.Equal(t, [][]float32{{25, 10, 5, 5, 2.5}}, OptimizeInventory(inv, &SolutionOpts{}))
+
+	// 135 needs 45 on each side
+	inv.MaxWeightPerSide = 42.5
+	assert.Nil(t, OptimizeInventory(inv, &SolutionOpts{}))
+}
@@ -3,7 +3,6 @@ package plans
 import (
 	"encoding/csv"
 	"fmt"
-	"log"
 
 	"github.com/kdeloach/platecalc"
 )
@@ -20,6 +19,7 @@ type custom531 struct {
 type custom531PlanWriter struct {
 	*csv.Writer
 	plan *custom531
+	days []*liftDay
 }
 
 func NewCustom531(settings *WorkoutPlanSettings) *custom531 {
@@ -28,17 +28,18 @@ func NewCustom531(settings *WorkoutPlanSettings) *custom531 {
 	}
 }
 
-func (plan *custom531) Write(w *csv.Writer) {
+func (plan *custom531) Write(w *csv.Writer) error {
 	pw := &custom531PlanWriter{
 		Writer: w,
 		plan:   plan,
 	}
 
-	pw.writeHeader()
 	pw.writeWeek(1, []float32{0.50, 0.60, 0.80, 0.85, 0.40})
 	pw.writeWeek(2, []float32{0.55, 0.65, 0.85, 0.90, 0.40})
 	pw.writeWeek(3, []float32{0.60, 0.70, 0.90, 0.95, 0.40})
 	pw.writeWeek(4, []float32{0.40, 0.50, 0.60, 0.70, 0.40})
+
+	return plan.settings.solve(pw.days, pw.writeHeader)
 }
 
 func (pw *custom531PlanWriter) writeWeek(week int, tmPercs []float32) {
@@ -60,30 +61,35 @@ func (pw *custom531PlanWriter) writeDay(liftName string, week, day int, tmPercs
 		platecalc.FloorLimit(platecalc.RoundUpToNearest(repMax*tmPerc*tmPercs[4], 5), 45),
 	}
 
-	plates := pw.plan.settings.PlateCalcFn(setWeights)
-	if plates == nil {
-		log.Fatalf("no solution found for: %v setWeights=%v", liftName, setWeights)
-	}
-
 	sets := []int{5, 4, 2, 1, 3}
 	reps := []int{8, 6, 5, 5, 15}
+	// Only the heaviest set goes for extra reps, and not in week 4.
+	amrap := []bool{false, false, false, week < 4, false}
 
-	pw.writeRow(liftName, week, day, tmPercs[0], setWeights[0], plates[0], sets[0], reps[0])
-	pw.writeRow(liftName, week, day, tmPercs[1], setWeights[1], plates[1], sets[1], reps[1])
-	pw.writeRow(liftName, week, day, tmPercs[2], setWeights[2], plates[2], sets[2], reps[2])
-	pw.writeRow(liftName, week, day, tmPercs[3], setWeights[3], plates[3], sets[3], reps[3])
-	pw.writeRow(liftName, week, day, tmPercs[4], setWeights[4], plates[4], sets[4], reps[4])
+	pw.days = append(pw.days, &liftDay{
+		liftName:   liftName,
+		week:       week,
+		day:        day,
+		setWeights: setWeights,
+		write: func(plates, prev []*platecalc.Tree) {
+			pw.writeRow(liftName, week, day, tmPercs[0], setWeights[0], prev[0], plates[0], sets[0], reps[0], amrap[0])
+			pw.writeRow(liftName, week, day, tmPercs[1], setWeights[1], prev[1], plates[1], sets[1], reps[1], amrap[1])
+			pw.writeRow(liftName, week, day, tmPercs[2], setWeights[2], prev[2], plates[2], sets[2], reps[2], amrap[2])
+			pw.writeRow(liftName, week, day, tmPercs[3], setWeights[3], prev[3], plates[3], sets[3], reps[3], amrap[3])
+			pw.writeRow(liftName, week, day, tmPercs[4], setWeights[4], prev[4], plates[4], sets[4], reps[4], amrap[4])
+		},
+	})
 }
 
 func (pw *custom531PlanWriter) writeHeader() {
-	pw.Write([]string{
+	pw.Write(append([]string{
 		"Lift", "Week", "Day", "TM %", "Weight", "Plates", "Sets", "Reps",
-	})
+	}, pw.plan.settings.extraHeader()...))
 	pw.Flush()
 }
 
-func (pw *custom531PlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, plates *platecalc.Tree, sets int, reps int) {
-	pw.Write([]string{
+func (pw *custom531PlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, prev, plates *platecalc.Tree, sets int, reps int, amrap bool) {
+	pw.Write(append([]string{
 		liftName,
 		fmt.Sprintf("%v", week),
 		fmt.Sprintf("%v", day),
@@ -91,7 +97,7 @@ func (pw *custom531PlanWriter) writeRow(liftName string, week int, day int, tmPe
 		fmt.Sprintf("%v", weight),
 		plates.String(),
 		fmt.Sprintf("%v", sets),
-		fmt.Sprintf("%v", reps),
-	})
+		repsColumn(reps, amrap),
+	}, pw.plan.settings.extraColumns(prev, plates)...))
 	pw.Flush()
 }
@@ -2,9 +2,11 @@ package plans
 
 import (
 	"encoding/csv"
+	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/kdeloach/platecalc"
 )
@@ -17,23 +19,199 @@ var (
 )
 
 type WorkoutPlan interface {
-	Write(w *csv.Writer)
+	Write(w *csv.Writer) error
 }
 
 type WorkoutPlanSettings struct {
-	Plan               string `yaml:"Plan"`
-	Plates             string `yaml:"Plates"`
-	SquatRepMax        int    `yaml:"SquatRepMax"`
-	DeadliftRepMax     int    `yaml:"DeadliftRepMax"`
-	PressRepMax        int    `yaml:"PressRepMax"`
-	BenchRepMax        int    `yaml:"BenchRepMax"`
-	TrainingMaxPercent int    `yaml:"TrainingMaxPercent"`
-	Progression5s      bool   `yaml:"Progression5s"`
-	PreferLessPlates   bool   `yaml:"PreferLessPlates"`
+	Plan               string     `yaml:"Plan"`
+	Plates             string     `yaml:"Plates"`
+	SquatRepMax        int        `yaml:"SquatRepMax"`
+	DeadliftRepMax     int        `yaml:"DeadliftRepMax"`
+	PressRepMax        int        `yaml:"PressRepMax"`
+	BenchRepMax        int        `yaml:"BenchRepMax"`
+	TrainingMaxPercent int        `yaml:"TrainingMaxPercent"`
+	Progression5s      bool       `yaml:"Progression5s"`
+	PreferLessPlates   bool       `yaml:"PreferLessPlates"`
+	CollarWeight       float32    `yaml:"CollarWeight"`
+	WarmupSets         int        `yaml:"WarmupSets"`    // Number of leading sets of each lift which are warm-up sets
+	WarmupCollars      bool       `yaml:"WarmupCollars"` // Use collars on warm-up sets
+	MaxImbalance       float32    `yaml:"MaxImbalance"`  // Largest plate which may be loaded on one side only
+	SharedBar          [][]string `yaml:"SharedBar"`     // Lifts on the same day which share a bar
 	PlateCalcFn        PlateCalcFunction
+	DrawFn             func(prev, plates *platecalc.Tree) string // Draws the bar for the Diagram column, if set
+	Workers            int                                       `yaml:"-"` // Number of sessions to solve concurrently
 }
 
-type PlateCalcFunction func(setWeights []int) []*platecalc.Tree
+// PlateCalcFunction returns the plates for each set weight of each lift in
+// lifts, or nil if there is no solution. The lifts are performed one after
+// another on the same bar, as solved by platecalc.SessionSolutionContext. It is
+// called from multiple goroutines when WorkoutPlanSettings.Workers is greater
+// than one.
+type PlateCalcFunction func(lifts [][]int) [][]*platecalc.Tree
+
+// LiftWeights returns the set weights of each lift passed to a
+// PlateCalcFunction as weights for the solver.
+func LiftWeights(lifts [][]int) [][]float32 {
+	result := make([][]float32, len(lifts))
+	for i, setWeights := range lifts {
+		result[i] = make([]float32, len(setWeights))
+		for j, weight := range setWeights {
+			result[i][j] = float32(weight)
+		}
+	}
+	return result
+}
+
+// liftDay is the sequence of sets for one lift on one day, which is solved as
+// a whole and written once the plates for each set are known. Prev is the
+// loading before each set, or nil for the first set of a session.
+type liftDay struct {
+	liftName   string
+	week, day  int
+	setWeights []int
+	write      func(plates, prev []*platecalc.Tree)
+}
+
+// session is one or more consecutive lift days on the same day which share a
+// bar and are solved as a single sequence of sets.
+type session []*liftDay
+
+func (sess session) lifts() [][]int {
+	lifts := make([][]int, len(sess))
+	for i, d := range sess {
+		lifts[i] = d.setWeights
+	}
+	return lifts
+}
+
+func (sess session) setWeights() []int {
+	setWeights := make([]int, 0)
+	for _, d := range sess {
+		setWeights = append(setWeights, d.setWeights...)
+	}
+	return setWeights
+}
+
+func (sess session) String() string {
+	names := make([]string, len(sess))
+	for i, d := range sess {
+		names[i] = d.liftName
+	}
+	return strings.Join(names, ", ")
+}
+
+// sharesBar returns true if liftA and liftB are in the same SharedBar group.
+func (settings *WorkoutPlanSettings) sharesBar(liftA, liftB string) bool {
+	for _, group := range settings.SharedBar {
+		hasA, hasB := false, false
+		for _, liftName := range group {
+			hasA = hasA || liftName == liftA
+			hasB = hasB || liftName == liftB
+		}
+		if hasA && hasB {
+			return true
+		}
+	}
+	return false
+}
+
+// sessions groups consecutive lift days on the same day which share a bar.
+func (settings *WorkoutPlanSettings) sessions(days []*liftDay) []session {
+	result := make([]session, 0)
+	for _, d := range days {
+		if n := len(result); n > 0 {
+			last := result[n-1][len(result[n-1])-1]
+			if last.week == d.week && last.day == d.day && settings.sharesBar(last.liftName, d.liftName) {
+				result[n-1] = append(result[n-1], d)
+				continue
+			}
+		}
+		result = append(result, session{d})
+	}
+	return result
+}
+
+// solve calls PlateCalcFn for every session using up to settings.Workers
+// goroutines, then writes the header and each lift day in order. Nothing is
+// written unless every session has a solution.
+func (settings *WorkoutPlanSettings) solve(days []*liftDay, writeHeader func()) error {
+	workers := settings.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	sessions := settings.sessions(days)
+	results := make([][][]*platecalc.Tree, len(sessions))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j] = settings.PlateCalcFn(sessions[j].lifts())
+			}
+		}()
+	}
+	for i := range sessions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, sess := range sessions {
+		if results[i] == nil {
+			return fmt.Errorf("no solution found for: %v setWeights=%v", sess, sess.setWeights())
+		}
+	}
+
+	writeHeader()
+	for i, sess := range sessions {
+		var last *platecalc.Tree
+		for j, plates := range results[i] {
+			prev := make([]*platecalc.Tree, len(plates))
+			for k, node := range plates {
+				prev[k] = last
+				last = node
+			}
+			sess[j].write(plates, prev)
+		}
+	}
+	return nil
+}
+
+// extraHeader returns the headers of the optional columns written after the
+// columns of each plan.
+func (settings *WorkoutPlanSettings) extraHeader() []string {
+	if settings.DrawFn != nil {
+		return []string{"Changes", "Diagram"}
+	}
+	return []string{"Changes"}
+}
+
+// extraColumns returns the optional columns for a set loaded with plates
+// after prev, which may be nil.
+func (settings *WorkoutPlanSettings) extraColumns(prev, plates *platecalc.Tree) []string {
+	changes := platecalc.NewTransition(prev, plates).String()
+	if settings.DrawFn != nil {
+		return []string{changes, settings.DrawFn(prev, plates)}
+	}
+	return []string{changes}
+}
+
+// NewWorkoutPlan returns the workout plan named by settings.Plan.
+func NewWorkoutPlan(settings *WorkoutPlanSettings) (WorkoutPlan, error) {
+	switch settings.Plan {
+	case "Wendler531BBB":
+		return NewWendler531BBB(settings), nil
+	case "Custom531":
+		return NewCustom531(settings), nil
+	case "Stronglifts":
+		return NewStrongliftsPlan(settings), nil
+	}
+	return nil, fmt.Errorf("unknown plan: %s", settings.Plan)
+}
 
 func (settings *WorkoutPlanSettings) repMax(liftName string) int {
 	switch liftName {
@@ -50,6 +228,15 @@ func (settings *WorkoutPlanSettings) repMax(liftName string) int {
 	return 0
 }
 
+// repsColumn formats reps for the Reps column. AMRAP sets, as many reps as
+// possible with reps as the minimum, are written as "5+".
+func repsColumn(reps int, amrap bool) string {
+	if amrap {
+		return fmt.Sprintf("%v+", reps)
+	}
+	return fmt.Sprintf("%v", reps)
+}
+
 func ParsePlates(strPlates string) ([]float32, error) {
 	plates := []float32{}
 	for _, s := range strings.Split(strPlates, ",") {
@@ -0,0 +1,119 @@
+package plans
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kdeloach/platecalc"
+)
+
+func TestRepsColumn(t *testing.T) {
+	assert.Equal(t, "5", repsColumn(5, false))
+	assert.Equal(t, "5+", repsColumn(5, true))
+	assert.Equal(t, "1+", repsColumn(1, true))
+}
+
+// writePlan writes plan with every set loaded as the empty bar and returns
+// the rows after the header.
+func writePlan(t *testing.T, settings *WorkoutPlanSettings, newPlan func(*WorkoutPlanSettings) WorkoutPlan) [][]string {
+	settings.SquatRepMax = 300
+	settings.DeadliftRepMax = 310
+	settings.PressRepMax = 145
+	settings.BenchRepMax = 205
+	settings.TrainingMaxPercent = 90
+	settings.PlateCalcFn = func(lifts [][]int) [][]*platecalc.Tree {
+		bar := platecalc.NewTree(nil, 45)
+		result := make([][]*platecalc.Tree, len(lifts))
+		for i, setWeights := range lifts {
+			result[i] = make([]*platecalc.Tree, len(setWeights))
+			for j := range setWeights {
+				result[i][j] = bar
+			}
+		}
+		return result
+	}
+
+	var buf bytes.Buffer
+	err := newPlan(settings).Write(csv.NewWriter(&buf))
+	assert.Nil(t, err)
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, "Reps", rows[0][7])
+	return rows[1:]
+}
+
+// amrapReps returns the reps column of each AMRAP Squat row by week.
+func amrapReps(rows [][]string) map[string][]string {
+	result := make(map[string][]string)
+	for _, row := range rows {
+		if row[0] == SQUAT && strings.HasSuffix(row[7], "+") {
+			result[row[1]] = append(result[row[1]], row[7])
+		}
+	}
+	return result
+}
+
+func TestWendler531BBBAmrap(t *testing.T) {
+	rows := writePlan(t, &WorkoutPlanSettings{}, func(s *WorkoutPlanSettings) WorkoutPlan {
+		return NewWendler531BBB(s)
+	})
+	assert.Equal(t, map[string][]string{
+		"1": {"5+"},
+		"2": {"3+"},
+		"3": {"1+"},
+	}, amrapReps(rows))
+
+	rows = writePlan(t, &WorkoutPlanSettings{Progression5s: true}, func(s *WorkoutPlanSettings) WorkoutPlan {
+		return NewWendler531BBB(s)
+	})
+	assert.Empty(t, amrapReps(rows))
+}
+
+func TestCustom531Amrap(t *testing.T) {
+	for _, progression5s := range []bool{false, true} {
+		rows := writePlan(t, &WorkoutPlanSettings{Progression5s: progression5s}, func(s *WorkoutPlanSettings) WorkoutPlan {
+			return NewCustom531(s)
+		})
+		assert.Equal(t, map[string][]string{
+			"1": {"5+"},
+			"2": {"5+"},
+			"3": {"5+"},
+		}, amrapReps(rows))
+	}
+}
+
+func TestSolveNoSolution(t *testing.T) {
+	settings := &WorkoutPlanSettings{
+		SquatRepMax:        300,
+		DeadliftRepMax:     310,
+		PressRepMax:        145,
+		BenchRepMax:        205,
+		TrainingMaxPercent: 90,
+		Workers:            4,
+	}
+	// every session but the heaviest deadlift day can be loaded
+	settings.PlateCalcFn = func(lifts [][]int) [][]*platecalc.Tree {
+		bar := platecalc.NewTree(nil, 45)
+		result := make([][]*platecalc.Tree, len(lifts))
+		for i, setWeights := range lifts {
+			result[i] = make([]*platecalc.Tree, len(setWeights))
+			for j, weight := range setWeights {
+				if weight > 260 {
+					return nil
+				}
+				result[i][j] = bar
+			}
+		}
+		return result
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	assert.Error(t, NewWendler531BBB(settings).Write(w))
+	w.Flush()
+	assert.Empty(t, buf.String())
+}
@@ -3,7 +3,6 @@ package plans
 import (
 	"encoding/csv"
 	"fmt"
-	"log"
 
 	"github.com/kdeloach/platecalc"
 )
@@ -15,6 +14,7 @@ type strongliftsPlan struct {
 type strongliftsPlanWriter struct {
 	*csv.Writer
 	plan *strongliftsPlan
+	days []*liftDay
 }
 
 func NewStrongliftsPlan(settings *WorkoutPlanSettings) *strongliftsPlan {
@@ -23,17 +23,18 @@ func NewStrongliftsPlan(settings *WorkoutPlanSettings) *strongliftsPlan {
 	}
 }
 
-func (plan *strongliftsPlan) Write(w *csv.Writer) {
+func (plan *strongliftsPlan) Write(w *csv.Writer) error {
 	pw := &strongliftsPlanWriter{
 		Writer: w,
 		plan:   plan,
 	}
 
-	pw.writeHeader()
 	pw.writeWeek(1)
 	pw.writeWeek(2)
 	pw.writeWeek(3)
 	pw.writeWeek(4)
+
+	return plan.settings.solve(pw.days, pw.writeHeader)
 }
 
 func (pw *strongliftsPlanWriter) writeWeek(week int) {
@@ -78,27 +79,30 @@ func (pw *strongliftsPlanWriter) writeLift(liftName string, week, day int) {
 		platecalc.RoundUpToNearest(repMax*tmPerc+inc, 5),
 	}
 
-	plates := pw.plan.settings.PlateCalcFn(setWeights)
-	if plates == nil {
-		log.Fatalf("no solution found for: %v setWeights=%v", liftName, setWeights)
-	}
-
-	if liftName == DEADLIFT {
-		pw.writeRow(liftName, week, day, tmPerc, setWeights[0], plates[0], 1, 5)
-	} else {
-		pw.writeRow(liftName, week, day, tmPerc, setWeights[0], plates[0], 5, 5)
-	}
+	pw.days = append(pw.days, &liftDay{
+		liftName:   liftName,
+		week:       week,
+		day:        day,
+		setWeights: setWeights,
+		write: func(plates, prev []*platecalc.Tree) {
+			if liftName == DEADLIFT {
+				pw.writeRow(liftName, week, day, tmPerc, setWeights[0], prev[0], plates[0], 1, 5)
+			} else {
+				pw.writeRow(liftName, week, day, tmPerc, setWeights[0], prev[0], plates[0], 5, 5)
+			}
+		},
+	})
 }
 
 func (pw *strongliftsPlanWriter) writeHeader() {
-	pw.Write([]string{
+	pw.Write(append([]string{
 		"Week", "Day", "Lift", "Weight", "Plates", "Sets", "Reps", "TM %",
-	})
+	}, pw.plan.settings.extraHeader()...))
 	pw.Flush()
 }
 
-func (pw *strongliftsPlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, plates *platecalc.Tree, sets int, reps int) {
-	pw.Write([]string{
+func (pw *strongliftsPlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, prev, plates *platecalc.Tree, sets int, reps int) {
+	pw.Write(append([]string{
 		fmt.Sprintf("%v", week),
 		fmt.Sprintf("%v", day),
 		liftName,
@@ -107,6 +111,6 @@ func (pw *strongliftsPlanWriter) writeRow(liftName string, week int, day int, tm
 		fmt.Sprintf("%v", sets),
 		fmt.Sprintf("%v", reps),
 		fmt.Sprintf("%v%%", int(tmPerc*100)),
-	})
+	}, pw.plan.settings.extraColumns(prev, plates)...))
 	pw.Flush()
 }
@@ -3,7 +3,6 @@ package plans
 import (
 	"encoding/csv"
 	"fmt"
-	"log"
 
 	"github.com/kdeloach/platecalc"
 )
@@ -15,6 +14,7 @@ type wendler531BBB struct {
 type wendler531BBBPlanWriter struct {
 	*csv.Writer
 	plan *wendler531BBB
+	days []*liftDay
 }
 
 func NewWendler531BBB(settings *WorkoutPlanSettings) *wendler531BBB {
@@ -23,17 +23,18 @@ func NewWendler531BBB(settings *WorkoutPlanSettings) *wendler531BBB {
 	}
 }
 
-func (plan *wendler531BBB) Write(w *csv.Writer) {
+func (plan *wendler531BBB) Write(w *csv.Writer) error {
 	pw := &wendler531BBBPlanWriter{
 		Writer: w,
 		plan:   plan,
 	}
 
-	pw.writeHeader()
 	pw.writeWeek(1, []float32{0.65, 0.75, 0.85, 0.60})
 	pw.writeWeek(2, []float32{0.70, 0.80, 0.90, 0.60})
 	pw.writeWeek(3, []float32{0.75, 0.85, 0.95, 0.60})
 	pw.writeWeek(4, []float32{0.50, 0.60, 0.70, 0.60})
+
+	return plan.settings.solve(pw.days, pw.writeHeader)
 }
 
 func (pw *wendler531BBBPlanWriter) writeWeek(week int, tmPercs []float32) {
@@ -52,12 +53,6 @@ func (pw *wendler531BBBPlanWriter) writeDay(liftName string, week, day int, tmPe
 		platecalc.RoundUpToNearest(repMax*tmPerc*tmPercs[1], 5),
 		platecalc.RoundUpToNearest(repMax*tmPerc*tmPercs[2], 5),
 		platecalc.RoundUpToNearest(repMax*tmPerc*tmPercs[3], 5),
-		platecalc.RoundUpToNearest(repMax*tmPerc*tmPercs[4], 5),
-	}
-
-	plates := pw.plan.settings.PlateCalcFn(setWeights)
-	if plates == nil {
-		log.Fatalf("no solution found for: %v setWeights=%v", liftName, setWeights)
 	}
 
 	var reps []int
@@ -74,24 +69,36 @@ func (pw *wendler531BBBPlanWriter) writeDay(liftName string, week, day int, tmPe
 		}
 	}
 
-	// Wendler 531 main lifts
-	pw.writeRow(liftName, week, day, tmPercs[0], setWeights[0], plates[0], 1, reps[0])
-	pw.writeRow(liftName, week, day, tmPercs[1], setWeights[1], plates[1], 1, reps[1])
-	pw.writeRow(liftName, week, day, tmPercs[2], setWeights[2], plates[2], 1, reps[2])
-
-	// Wendler BBB 5x10 supplemental lift
-	pw.writeRow(liftName, week, day, tmPercs[3], setWeights[3], plates[3], 5, 10)
+	// Wendler's top set is "5+", "3+" or "1+" in weeks 1-3. 5s progression
+	// caps it at 5 and week 4 is a deload, so neither goes past the reps.
+	amrap := week < 4 && !pw.plan.settings.Progression5s
+
+	pw.days = append(pw.days, &liftDay{
+		liftName:   liftName,
+		week:       week,
+		day:        day,
+		setWeights: setWeights,
+		write: func(plates, prev []*platecalc.Tree) {
+			// Wendler 531 main lifts
+			pw.writeRow(liftName, week, day, tmPercs[0], setWeights[0], prev[0], plates[0], 1, reps[0], false)
+			pw.writeRow(liftName, week, day, tmPercs[1], setWeights[1], prev[1], plates[1], 1, reps[1], false)
+			pw.writeRow(liftName, week, day, tmPercs[2], setWeights[2], prev[2], plates[2], 1, reps[2], amrap)
+
+			// Wendler BBB 5x10 supplemental lift
+			pw.writeRow(liftName, week, day, tmPercs[3], setWeights[3], prev[3], plates[3], 5, 10, false)
+		},
+	})
 }
 
 func (pw *wendler531BBBPlanWriter) writeHeader() {
-	pw.Write([]string{
+	pw.Write(append([]string{
 		"Lift", "Week", "Day", "TM %", "Weight", "Plates", "Sets", "Reps",
-	})
+	}, pw.plan.settings.extraHeader()...))
 	pw.Flush()
 }
 
-func (pw *wendler531BBBPlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, plates *platecalc.Tree, sets int, reps int) {
-	pw.Write([]string{
+func (pw *wendler531BBBPlanWriter) writeRow(liftName string, week int, day int, tmPerc float32, weight int, prev, plates *platecalc.Tree, sets int, reps int, amrap bool) {
+	pw.Write(append([]string{
 		liftName,
 		fmt.Sprintf("%v", week),
 		fmt.Sprintf("%v", day),
@@ -99,7 +106,7 @@ func (pw *wendler531BBBPlanWriter) writeRow(liftName string, week int, day int,
 		fmt.Sprintf("%v", weight),
 		plates.String(),
 		fmt.Sprintf("%v", sets),
-		fmt.Sprintf("%v", reps),
-	})
+		repsColumn(reps, amrap),
+	}, pw.plan.settings.extraColumns(prev, plates)...))
 	pw.Flush()
 }
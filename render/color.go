@@ -0,0 +1,62 @@
+package render
+
+import "math"
+
+type Color int
+
+const (
+	Red Color = iota
+	Blue
+	Yellow
+	Green
+	White
+)
+
+// iwfColors are the IWF colors of competition plates by weight in
+// kilograms.
+var iwfColors = []struct {
+	kg    float32
+	color Color
+}{
+	{25, Red},
+	{20, Blue},
+	{15, Yellow},
+	{10, Green},
+	{5, White},
+	{2.5, Red},
+	{2, Blue},
+	{1.5, Yellow},
+	{1, Green},
+	{0.5, White},
+}
+
+// PlateColor returns the IWF color of the competition plate closest in weight
+// to p.
+func PlateColor(p float32, kg bool) Color {
+	w := kilograms(p, kg)
+	best := iwfColors[0]
+	for _, c := range iwfColors[1:] {
+		if math.Abs(float64(c.kg-w)) < math.Abs(float64(best.kg-w)) {
+			best = c
+		}
+	}
+	return best.color
+}
+
+var ansiColors = map[Color]string{
+	Red:    "\x1b[31m",
+	Blue:   "\x1b[34m",
+	Yellow: "\x1b[33m",
+	Green:  "\x1b[32m",
+	White:  "\x1b[37m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// colorize wraps s in the ANSI color for plate p if opts.Color is set.
+func colorize(s string, p float32, opts *TextOpts) string {
+	if !opts.Color {
+		return s
+	}
+	return ansiColors[PlateColor(p, opts.Kg)] + s + ansiReset
+}
@@ -0,0 +1,191 @@
+// Package render draws the plates loaded on a bar.
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kdeloach/platecalc"
+)
+
+type Style int
+
+const (
+	ASCII Style = iota
+	Unicode
+)
+
+// ParseStyle returns the style named "ascii" or "unicode".
+func ParseStyle(name string) (Style, error) {
+	switch name {
+	case "ascii":
+		return ASCII, nil
+	case "unicode":
+		return Unicode, nil
+	}
+	return ASCII, fmt.Errorf("unknown style: %s (expected ascii or unicode)", name)
+}
+
+type TextOpts struct {
+	Style   Style
+	Color   bool // Color plates with ANSI escape codes
+	Kg      bool // Plates are in kilograms
+	Collars bool // Draw collars inside the plates
+}
+
+// maxLevel is the height in rows above and below the bar of the heaviest
+// plate drawn by Text.
+const maxLevel = 5
+
+// shaftLength is the number of columns between the sleeves.
+const shaftLength = 8
+
+// Text returns a drawing of the bar with each plate drawn at a height
+// proportional to its weight.
+func Text(node *platecalc.Tree, opts *TextOpts) string {
+	bar, plate, collarL, collarR := "=", "#", "]", "["
+	if opts.Style == Unicode {
+		bar, plate, collarL, collarR = "━", "█", "▐", "▌"
+	}
+
+	// each column is a plate (with its height) or a piece of the bar
+	type column struct {
+		value float32
+		level int
+		glyph string
+	}
+	columns := make([]column, 0)
+	addBar := func(n int, glyph string) {
+		for i := 0; i < n; i++ {
+			columns = append(columns, column{glyph: glyph})
+		}
+	}
+	addPlate := func(p float32) {
+		columns = append(columns, column{value: p, level: level(p, opts.Kg, maxLevel), glyph: plate})
+	}
+
+	left, right := sides(node)
+	if left != nil {
+		addBar(2, bar)
+		for i := len(left) - 1; i >= 0; i-- {
+			addPlate(left[i])
+		}
+		if opts.Collars {
+			addBar(1, collarL)
+		}
+	}
+	addBar(shaftLength, bar)
+	if opts.Collars {
+		addBar(1, collarR)
+	}
+	for _, p := range right {
+		addPlate(p)
+	}
+	addBar(2, bar)
+
+	height := 1
+	for _, c := range columns {
+		if c.level > height {
+			height = c.level
+		}
+	}
+
+	lines := make([]string, 0, height*2-1)
+	for row := height - 1; row > -height; row-- {
+		var sb strings.Builder
+		for _, c := range columns {
+			dist := row
+			if dist < 0 {
+				dist = -dist
+			}
+			switch {
+			case c.level > dist:
+				sb.WriteString(colorize(c.glyph, c.value, opts))
+			case row == 0:
+				sb.WriteString(c.glyph)
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Line returns a drawing of the bar on a single line. ASCII plates are drawn
+// as their weight and Unicode plates as a block proportional to their weight.
+func Line(node *platecalc.Tree, opts *TextOpts) string {
+	bar, collarL, collarR := "=", "]", "["
+	if opts.Style == Unicode {
+		bar, collarL, collarR = "━", "▐", "▌"
+	}
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	drawPlate := func(p float32) string {
+		if opts.Style == Unicode {
+			return colorize(string(blocks[level(p, opts.Kg, len(blocks))-1]), p, opts)
+		}
+		return colorize(fmt.Sprintf("|%v", p), p, opts)
+	}
+
+	var sb strings.Builder
+	left, right := sides(node)
+	if left != nil {
+		sb.WriteString(bar)
+		for i := len(left) - 1; i >= 0; i-- {
+			sb.WriteString(drawPlate(left[i]))
+		}
+		if opts.Style == ASCII && len(left) > 0 {
+			sb.WriteString("|")
+		}
+		if opts.Collars {
+			sb.WriteString(collarL)
+		}
+	}
+	sb.WriteString(strings.Repeat(bar, shaftLength/2))
+	if opts.Collars {
+		sb.WriteString(collarR)
+	}
+	for _, p := range right {
+		sb.WriteString(drawPlate(p))
+	}
+	if opts.Style == ASCII && len(right) > 0 {
+		sb.WriteString("|")
+	}
+	sb.WriteString(bar)
+	return sb.String()
+}
+
+// sides returns the plates on the left and right sleeves, innermost first.
+// Left is nil for implements with a single sleeve.
+func sides(node *platecalc.Tree) ([]float32, []float32) {
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	if root.Sleeves < 2 {
+		return nil, node.Plates()
+	}
+	return node.Left(), node.Right()
+}
+
+// level returns the height of plate p from 1 to max, proportional to its
+// weight relative to a 25 kg plate.
+func level(p float32, kg bool, max int) int {
+	n := int(math.Round(float64(kilograms(p, kg)) * float64(max) / 25))
+	if n < 1 {
+		return 1
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+func kilograms(p float32, kg bool) float32 {
+	if kg {
+		return p
+	}
+	return platecalc.LbToKg(p)
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kdeloach/platecalc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	tree := platecalc.NewTree(nil, 45)
+	node := tree.Add(45, 10)
+
+	want := strings.Join([]string{
+		"   #        #",
+		"   #        #",
+		"   #        #",
+		"==##========##==",
+		"   #        #",
+		"   #        #",
+		"   #        #",
+	}, "\n")
+	assert.Equal(t, want, Text(node, &TextOpts{}))
+
+	assert.Equal(t, "━━━━━━━━━━━━", Text(tree, &TextOpts{Style: Unicode}))
+
+	landmine := platecalc.NewImplementTree(0, 1)
+	assert.Equal(t, "========[#==", Text(landmine.Add(10), &TextOpts{Collars: true}))
+}
+
+func TestLine(t *testing.T) {
+	tree := platecalc.NewTree(nil, 45)
+	node := tree.Add(45, 10)
+
+	assert.Equal(t, "=|10|45|]====[|45|10|=", Line(node, &TextOpts{Collars: true}))
+	assert.Equal(t, "━▁▇━━━━▇▁━", Line(node, &TextOpts{Style: Unicode}))
+	assert.Equal(t, "======", Line(tree, &TextOpts{}))
+}
+
+func TestPlateColor(t *testing.T) {
+	assert.Equal(t, Red, PlateColor(25, true))
+	assert.Equal(t, Blue, PlateColor(45, false))
+	assert.Equal(t, Yellow, PlateColor(35, false))
+	assert.Equal(t, Green, PlateColor(25, false))
+	assert.Equal(t, White, PlateColor(10, false))
+	assert.Equal(t, "\x1b[34m#\x1b[0m", colorize("#", 20, &TextOpts{Color: true, Kg: true}))
+}
+
+func TestSVG(t *testing.T) {
+	tree := platecalc.NewTree(nil, 45)
+	node := tree.Add(45, 10)
+
+	svg := SVG(node, &SVGOpts{})
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="400"`))
+	assert.Equal(t, 2, strings.Count(svg, ">45</text>"))
+	assert.Equal(t, 2, strings.Count(svg, ">10</text>"))
+	assert.NotContains(t, svg, "stroke-dasharray")
+
+	landmine := platecalc.NewImplementTree(0, 1)
+	assert.Equal(t, 1, strings.Count(SVG(landmine.Add(45), &SVGOpts{}), ">45</text>"))
+}
+
+func TestDiffSVG(t *testing.T) {
+	tree := platecalc.NewTree(nil, 45)
+	prev := tree.Add(45, 25, 10)
+	node := tree.Add(45, 25, 5)
+
+	svg := DiffSVG(prev, node, &SVGOpts{})
+	assert.Equal(t, 2, strings.Count(svg, "stroke-dasharray"))
+	assert.Equal(t, 2, strings.Count(svg, `stroke="#00c853"`))
+	assert.Equal(t, 0, strings.Count(svg, ">10</text>"))
+	assert.Equal(t, 2, strings.Count(svg, ">5</text>"))
+
+	// the first set adds every plate to the empty bar
+	svg = DiffSVG(nil, node, &SVGOpts{})
+	assert.Equal(t, 6, strings.Count(svg, `stroke="#00c853"`))
+	assert.NotContains(t, svg, "stroke-dasharray")
+	assert.NotContains(t, SVG(node, &SVGOpts{}), `stroke="#00c853"`)
+
+	assert.Equal(t, 0, commonDepth(nil, node))
+	assert.Equal(t, 0, commonDepth(tree.Add(25), prev))
+	assert.Equal(t, 2, commonDepth(prev, node))
+	assert.Equal(t, 3, commonDepth(prev, prev))
+}
@@ -0,0 +1,231 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kdeloach/platecalc"
+)
+
+type SVGOpts struct {
+	Kg      bool // Plates are in kilograms
+	Collars bool // Draw collars outside the plates
+	Width   int  // Width in pixels (400 if zero)
+}
+
+// plateSize is the diameter and thickness of a plate in millimeters.
+type plateSize struct {
+	weight    float32
+	diameter  float32
+	thickness float32
+}
+
+// Sizes of competition bumper plates and iron plates, heaviest first.
+var kgSizes = []plateSize{
+	{25, 450, 54},
+	{20, 450, 45},
+	{15, 450, 35},
+	{10, 450, 28},
+	{5, 230, 24},
+	{2.5, 210, 19},
+	{2, 190, 19},
+	{1.5, 175, 17},
+	{1, 160, 15},
+	{0.5, 135, 12},
+}
+
+var lbSizes = []plateSize{
+	{55, 450, 48},
+	{45, 450, 40},
+	{35, 360, 34},
+	{25, 290, 30},
+	{10, 230, 24},
+	{5, 200, 20},
+	{2.5, 160, 15},
+	{1.25, 120, 12},
+}
+
+// sizeOf returns the size of the plate closest in weight to p.
+func sizeOf(p float32, kg bool) plateSize {
+	sizes := lbSizes
+	if kg {
+		sizes = kgSizes
+	}
+	best := sizes[0]
+	for _, s := range sizes[1:] {
+		if math.Abs(float64(s.weight-p)) < math.Abs(float64(best.weight-p)) {
+			best = s
+		}
+	}
+	return best
+}
+
+var svgColors = map[Color]string{
+	Red:    "#d32f2f",
+	Blue:   "#1565c0",
+	Yellow: "#f9a825",
+	Green:  "#2e7d32",
+	White:  "#eeeeee",
+}
+
+// Dimensions of the bar in millimeters. The shaft is drawn shorter than a
+// real bar so the plates are easier to see.
+const (
+	svgHeight      = 500
+	svgCenter      = 240
+	sleeveLength   = 415
+	sleeveDiameter = 50
+	shaftDrawn     = 300
+	shaftDiameter  = 28
+	collarWidth    = 30
+	collarDiameter = 80
+	barCollarWidth = 30
+	barCollarDia   = 70
+)
+
+// diffState marks plates added or removed by a plate change.
+type diffState int
+
+const (
+	unchanged diffState = iota
+	added
+	removed
+)
+
+// SVG returns an SVG drawing of node with each plate drawn at its realistic
+// relative diameter and thickness and labelled with its weight.
+func SVG(node *platecalc.Tree, opts *SVGOpts) string {
+	return diffSVG(nil, node, node.Depth, opts)
+}
+
+// DiffSVG is SVG but highlights the plates added to prev to load node, and
+// outlines the plates removed from prev. Prev may be nil for the first set,
+// which is loaded on the empty bar, so every plate is highlighted.
+func DiffSVG(prev, node *platecalc.Tree, opts *SVGOpts) string {
+	return diffSVG(prev, node, commonDepth(prev, node), opts)
+}
+
+// diffSVG draws node with the plates after the first keep highlighted.
+func diffSVG(prev, node *platecalc.Tree, keep int, opts *SVGOpts) string {
+	width := opts.Width
+	if width == 0 {
+		width = 400
+	}
+
+	left, right := sides(node)
+	single := left == nil
+	viewWidth := 2*(sleeveLength+barCollarWidth) + shaftDrawn
+	if single {
+		viewWidth = sleeveLength + barCollarWidth + shaftDrawn
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, width*svgHeight/viewWidth, viewWidth, svgHeight)
+
+	// shaft and sleeves
+	shaftStart := sleeveLength + barCollarWidth
+	if single {
+		shaftStart = 0
+	}
+	rightSleeve := shaftStart + shaftDrawn + barCollarWidth
+	rect(&sb, float32(shaftStart), shaftDiameter, shaftDrawn, "#9e9e9e", "")
+	if !single {
+		rect(&sb, 0, sleeveDiameter, sleeveLength, "#bdbdbd", "")
+		rect(&sb, float32(sleeveLength), barCollarDia, barCollarWidth, "#757575", "")
+	}
+	rect(&sb, float32(rightSleeve-barCollarWidth), barCollarDia, barCollarWidth, "#757575", "")
+	rect(&sb, float32(rightSleeve), sleeveDiameter, sleeveLength, "#bdbdbd", "")
+
+	end := float32(rightSleeve)
+	if !single {
+		end = drawSide(&sb, left, keep, float32(sleeveLength), -1, added, opts)
+		if opts.Collars {
+			rect(&sb, end-collarWidth, collarDiameter, collarWidth, "#424242", "")
+		}
+	}
+	end = drawSide(&sb, right, keep, float32(rightSleeve), 1, added, opts)
+	if opts.Collars {
+		rect(&sb, end, collarDiameter, collarWidth, "#424242", "")
+	}
+
+	// plates removed from prev are outlined over node
+	if prev != nil {
+		prevLeft, prevRight := sides(prev)
+		if !single {
+			drawSide(&sb, prevLeft, keep, float32(sleeveLength), -1, removed, opts)
+		}
+		drawSide(&sb, prevRight, keep, float32(rightSleeve), 1, removed, opts)
+	}
+
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// drawSide draws plates outward from x in direction dir (-1 for the left
+// sleeve) and returns the x position after the outermost plate. Plates after
+// the first keep plates are drawn as state; when state is removed, only those
+// plates are drawn.
+func drawSide(sb *strings.Builder, plates []float32, keep int, x float32, dir float32, state diffState, opts *SVGOpts) float32 {
+	for i, p := range plates {
+		size := sizeOf(p, opts.Kg)
+		start := x
+		if dir < 0 {
+			start = x - size.thickness
+		}
+		s := unchanged
+		if i >= keep {
+			s = state
+		}
+		if state != removed || s == removed {
+			drawPlate(sb, p, start, size, s, opts)
+		}
+		x += dir * size.thickness
+	}
+	return x
+}
+
+func drawPlate(sb *strings.Builder, p float32, x float32, size plateSize, state diffState, opts *SVGOpts) {
+	color := PlateColor(p, opts.Kg)
+	switch state {
+	case removed:
+		rect(sb, x, size.diameter, size.thickness, "none", `stroke="#d50000" stroke-width="4" stroke-dasharray="10,6"`)
+		return
+	case added:
+		rect(sb, x, size.diameter, size.thickness, svgColors[color], `stroke="#00c853" stroke-width="8"`)
+	default:
+		rect(sb, x, size.diameter, size.thickness, svgColors[color], `stroke="#212121" stroke-width="2"`)
+	}
+
+	textColor := "#ffffff"
+	if color == White || color == Yellow {
+		textColor = "#212121"
+	}
+	cx := x + size.thickness/2
+	fmt.Fprintf(sb, `<text x="%v" y="%v" transform="rotate(-90 %v %v)" font-family="sans-serif" font-size="%v" fill="%s" text-anchor="middle" dominant-baseline="central">%v</text>`,
+		cx, svgCenter, cx, svgCenter, fontSize(size), textColor, p)
+}
+
+// fontSize returns a label size which fits inside a plate.
+func fontSize(size plateSize) float32 {
+	if size.thickness < 24 {
+		return size.thickness
+	}
+	return 24
+}
+
+// rect draws a rectangle centered vertically on the bar.
+func rect(sb *strings.Builder, x, height, width float32, fill, attrs string) {
+	fmt.Fprintf(sb, `<rect x="%v" y="%v" width="%v" height="%v" fill="%s" %s/>`,
+		x, svgCenter-height/2, width, height, fill, attrs)
+}
+
+// commonDepth returns the number of plates which are not removed to change
+// from prev to node. No plate is kept if prev is nil, the empty bar.
+func commonDepth(prev, node *platecalc.Tree) int {
+	if prev == nil {
+		return 0
+	}
+	return prev.Depth - len(platecalc.NewTransition(prev, node).Remove)
+}
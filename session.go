@@ -0,0 +1,109 @@
+package platecalc
+
+import (
+	"context"
+)
+
+// SessionSolution returns the optimal sequence of plate changes for several
+// lifts performed one after another on the same bar, including the plate
+// changes between the last set of one lift and the first set of the next. The
+// solution is split into one sequence per lift. Warm-up sets are counted from
+// the first set of each lift, so each lift starts with opts.WarmupSets sets
+// without collars, unlike BestSolution of every set in one sequence, where
+// only the first lift has warm-up sets.
+func SessionSolution(tree *Tree, lifts [][]float32, maxDistance int, opts *SolutionOpts) [][]*Tree {
+	solution, _ := SessionSolutionContext(context.Background(), tree, lifts, maxDistance, opts)
+	return solution
+}
+
+// SessionSolutionContext is SessionSolution but stops searching once ctx is
+// done or its deadline has passed. It returns whether the search was
+// exhaustive.
+func SessionSolutionContext(ctx context.Context, tree *Tree, lifts [][]float32, maxDistance int, opts *SolutionOpts) ([][]*Tree, bool) {
+	setWeights := make([]float32, 0)
+	lengths := make([]int, len(lifts))
+	for i, sets := range lifts {
+		setWeights = append(setWeights, opts.loadWeights(tree, sets)...)
+		lengths[i] = len(sets)
+	}
+
+	solution, exhaustive := bestSolution(ctx, tree, setWeights, maxDistance, opts)
+	if solution == nil {
+		return nil, exhaustive
+	}
+	return SplitSolution(solution, lengths), exhaustive
+}
+
+// PartnerSolution returns the optimal sequence of plate changes for two or
+// more lifters sharing a bar and taking turns: lifter A set 1, lifter B set 1,
+// lifter A set 2, and so on. Lifters with fewer sets drop out of the rotation
+// once they are done. The solution is split into one sequence per lifter.
+// Warm-up sets are counted from the first set of each lifter.
+func PartnerSolution(tree *Tree, lifters [][]float32, maxDistance int, opts *SolutionOpts) [][]*Tree {
+	solution, _ := PartnerSolutionContext(context.Background(), tree, lifters, maxDistance, opts)
+	return solution
+}
+
+// PartnerSolutionContext is PartnerSolution but stops searching once ctx is
+// done or its deadline has passed. It returns whether the search was
+// exhaustive.
+func PartnerSolutionContext(ctx context.Context, tree *Tree, lifters [][]float32, maxDistance int, opts *SolutionOpts) ([][]*Tree, bool) {
+	turns := PartnerTurns(lifters)
+
+	loadWeights := make([][]float32, len(lifters))
+	for i, sets := range lifters {
+		loadWeights[i] = opts.loadWeights(tree, sets)
+	}
+
+	setWeights := make([]float32, len(turns))
+	for i, turn := range turns {
+		setWeights[i] = loadWeights[turn.Lifter][turn.Set]
+	}
+
+	solution, exhaustive := bestSolution(ctx, tree, setWeights, maxDistance, opts)
+	if solution == nil {
+		return nil, exhaustive
+	}
+
+	result := make([][]*Tree, len(lifters))
+	for i, sets := range lifters {
+		result[i] = make([]*Tree, len(sets))
+	}
+	for i, turn := range turns {
+		result[turn.Lifter][turn.Set] = solution[i]
+	}
+	return result, exhaustive
+}
+
+// Turn is one set taken by a lifter sharing a bar.
+type Turn struct {
+	Lifter int // Index of the lifter
+	Set    int // Index of the set for the lifter
+}
+
+// PartnerTurns returns the order in which lifters sharing a bar take their
+// sets.
+func PartnerTurns(lifters [][]float32) []Turn {
+	turns := make([]Turn, 0)
+	for set := 0; ; set++ {
+		done := true
+		for i, sets := range lifters {
+			if set < len(sets) {
+				turns = append(turns, Turn{i, set})
+				done = false
+			}
+		}
+		if done {
+			return turns
+		}
+	}
+}
+
+// SplitSolution splits solution into consecutive sequences of each length.
+func SplitSolution(solution []*Tree, lengths []int) [][]*Tree {
+	result := make([][]*Tree, len(lengths))
+	for i, n := range lengths {
+		result[i], solution = solution[:n:n], solution[n:]
+	}
+	return result
+}
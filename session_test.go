@@ -0,0 +1,104 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionSolution(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	lifts := [][]float32{
+		{55, 65},
+		{75, 55},
+	}
+	result := SessionSolution(tree, lifts, 5, &SolutionOpts{})
+
+	got := make([][]string, 0)
+	for _, nodes := range result {
+		lift := make([]string, 0)
+		for _, node := range nodes {
+			lift = append(lift, node.String())
+		}
+		got = append(got, lift)
+	}
+
+	// same as solving every set in one sequence
+	want := [][]string{
+		{"5", "10"},
+		{"10, 5", "5"},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestSessionSolutionWarmups(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	lifts := [][]float32{
+		{55, 65},
+		{75, 55},
+	}
+	opts := &SolutionOpts{CollarWeight: 2.5, WarmupSets: 1}
+	result := SessionSolution(tree, lifts, 5, opts)
+
+	// the first set of each lift is loaded without collars
+	got := make([][]float32, 0)
+	for _, nodes := range result {
+		lift := make([]float32, 0)
+		for _, node := range nodes {
+			lift = append(lift, node.TotalWeight())
+		}
+		got = append(got, lift)
+	}
+	assert.Equal(t, [][]float32{{55, 60}, {75, 50}}, got)
+
+	// solving every set in one sequence only has one warm-up set
+	solution := BestSolution(tree, []float32{55, 65, 75, 55}, 5, opts)
+	assert.Equal(t, float32(70), solution[2].TotalWeight())
+}
+
+func TestPartnerSolution(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(5, 5, 10, 10, 2.5) {
+		tree.Add(p...)
+	}
+
+	lifters := [][]float32{
+		{55, 75, 75},
+		{65, 55},
+	}
+	opts := &SolutionOpts{}
+	result := PartnerSolution(tree, lifters, 5, opts)
+
+	// same as solving the sets in the order they are taken
+	want := BestSolution(tree, []float32{55, 65, 75, 55, 75}, 5, opts)
+	assert.Equal(t, [][]*Tree{
+		{want[0], want[2], want[4]},
+		{want[1], want[3]},
+	}, result)
+}
+
+func TestPartnerTurns(t *testing.T) {
+	got := PartnerTurns([][]float32{{1, 2, 3}, {4}, {5, 6}})
+	want := []Turn{
+		{0, 0}, {1, 0}, {2, 0},
+		{0, 1}, {2, 1},
+		{0, 2},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestSplitSolution(t *testing.T) {
+	tree := NewTree(nil, 0)
+	a, b, c := tree.Add(1), tree.Add(2), tree.Add(3)
+
+	got := SplitSolution([]*Tree{a, b, c}, []int{1, 0, 2})
+	assert.Equal(t, [][]*Tree{{a}, {}, {b, c}}, got)
+}
@@ -0,0 +1,83 @@
+package platecalc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transition is the plates to remove from and add to the bar to change from
+// one loading to the next. Each plate is the node in the tree it is loaded
+// at, so plates loaded on one side only can be told apart.
+type Transition struct {
+	Remove []*Tree // Plates to remove, outermost first
+	Add    []*Tree // Plates to add, innermost first
+}
+
+// NewTransition returns the plates to remove and add to change from to to,
+// following the path between them in the tree. From may be nil for the empty
+// bar.
+func NewTransition(from, to *Tree) Transition {
+	if from == nil {
+		from = to
+		for from.Parent != nil {
+			from = from.Parent
+		}
+	}
+
+	t := Transition{Remove: make([]*Tree, 0), Add: make([]*Tree, 0)}
+	a, b := from, to
+	for a.Depth > b.Depth {
+		t.Remove = append(t.Remove, a)
+		a = a.Parent
+	}
+	for b.Depth > a.Depth {
+		t.Add = append([]*Tree{b}, t.Add...)
+		b = b.Parent
+	}
+	for a != b {
+		t.Remove = append(t.Remove, a)
+		t.Add = append([]*Tree{b}, t.Add...)
+		a, b = a.Parent, b.Parent
+	}
+	return t
+}
+
+// Transitions returns the transition to each set in solution from the set
+// before it, starting from the empty bar.
+func Transitions(solution []*Tree) []Transition {
+	transitions := make([]Transition, len(solution))
+	var prev *Tree
+	for i, node := range solution {
+		transitions[i] = NewTransition(prev, node)
+		prev = node
+	}
+	return transitions
+}
+
+// String returns the plates to remove and add, such as "remove 2.5; add 10".
+// Plates loaded on one side only are marked "(left)".
+func (t Transition) String() string {
+	parts := make([]string, 0, 2)
+	if len(t.Remove) > 0 {
+		parts = append(parts, "remove "+formatNodes(t.Remove))
+	}
+	if len(t.Add) > 0 {
+		parts = append(parts, "add "+formatNodes(t.Add))
+	}
+	if len(parts) == 0 {
+		return "no change"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatNodes(nodes []*Tree) string {
+	s := make([]string, len(nodes))
+	for i, node := range nodes {
+		if node.Symmetric() {
+			s[i] = fmt.Sprintf("%v", node.Value)
+		} else {
+			s[i] = fmt.Sprintf("%v (left)", node.Value)
+		}
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,40 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition(t *testing.T) {
+	tree := NewTree(nil, 45)
+	prev := tree.Add(45, 10, 5)
+	node := tree.Add(45, 25)
+
+	tr := NewTransition(prev, node)
+	assert.Equal(t, []*Tree{tree.Find(45, 10, 5), tree.Find(45, 10)}, tr.Remove)
+	assert.Equal(t, []*Tree{tree.Find(45, 25)}, tr.Add)
+	assert.Equal(t, "remove 5, 10; add 25", tr.String())
+	assert.Equal(t, prev.Distance(node), len(tr.Remove)+len(tr.Add))
+
+	assert.Equal(t, "add 45, 25", NewTransition(nil, node).String())
+	assert.Equal(t, "remove 25, 45", NewTransition(node, tree).String())
+	assert.Equal(t, "no change", NewTransition(node, node).String())
+
+	single := tree.Find(45).AddSingle(2.5)
+	assert.Equal(t, "remove 25; add 2.5 (left)", NewTransition(node, single).String())
+}
+
+func TestTransitions(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(25, 10, 5, 2.5) {
+		tree.Add(p...)
+	}
+	solution := BestSolution(tree, []float32{100, 110, 120}, 5, &SolutionOpts{})
+
+	got := []string{}
+	for _, tr := range Transitions(solution) {
+		got = append(got, tr.String())
+	}
+	assert.Equal(t, []string{"add 25, 2.5", "add 5", "remove 5; add 10"}, got)
+}
@@ -3,26 +3,39 @@ package platecalc
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 )
 
+// Tree is a node in the tree of plate permutations. Children must only be
+// added with Add so they are visited in a stable order by Walk and WalkNearby.
+// A Tree is safe for concurrent use by multiple goroutines once it is no
+// longer modified with Add.
 type Tree struct {
 	Parent   *Tree
 	Depth    int
-	Children map[float32]*Tree
+	Children map[float32]*Tree // Plates loaded on both sides; see FindSingle for plates on one side
 	Value    float32
+	Sleeves  int // Number of sleeves the plate is loaded on
+
+	// children sorted by ascending plate value, with plates loaded on one
+	// side only after plates of the same value loaded on both sides
+	children []*Tree
 }
 
 func NewTree(parent *Tree, value float32) *Tree {
 	depth := 0
+	sleeves := 2
 	if parent != nil {
 		depth = parent.Depth + 1
+		sleeves = parent.Sleeves
 	}
 	return &Tree{
 		Parent:   parent,
 		Depth:    depth,
 		Children: make(map[float32]*Tree),
 		Value:    value,
+		Sleeves:  sleeves,
 	}
 }
 
@@ -48,7 +61,7 @@ func (t *Tree) TotalWeight() float32 {
 	if t.Parent == nil {
 		return t.Value
 	}
-	return t.Parent.TotalWeight() + t.Value*2
+	return t.Parent.TotalWeight() + t.Value*float32(t.Sleeves)
 }
 
 func (t *Tree) Find(plates ...float32) *Tree {
@@ -79,6 +92,7 @@ func (t *Tree) Add(plates ...float32) *Tree {
 	if !ok {
 		next = NewTree(t, plate)
 		t.Children[plate] = next
+		t.insertChild(next)
 	}
 
 	if len(rest) > 0 {
@@ -88,17 +102,143 @@ func (t *Tree) Add(plates ...float32) *Tree {
 	}
 }
 
+// Distance returns the number of plates which must be removed and added to
+// change from t to other. Both nodes must belong to the same tree.
+func (t *Tree) Distance(other *Tree) int {
+	a, b := t, other
+	dist := 0
+	for a.Depth > b.Depth {
+		a = a.Parent
+		dist++
+	}
+	for b.Depth > a.Depth {
+		b = b.Parent
+		dist++
+	}
+	for a != b {
+		a, b = a.Parent, b.Parent
+		dist += 2
+	}
+	return dist
+}
+
+// AddSingle adds a child for plate loaded on one side of the bar only. Plates
+// loaded on one side only have no children. The child is not in Children,
+// which would clash with plate loaded on both sides, so find it with
+// FindSingle.
+func (t *Tree) AddSingle(plate float32) *Tree {
+	if child := t.FindSingle(plate); child != nil {
+		return child
+	}
+	child := NewTree(t, plate)
+	child.Sleeves = 1
+	t.insertChild(child)
+	return child
+}
+
+// FindSingle returns the child for plate loaded on one side of the bar only.
+func (t *Tree) FindSingle(plate float32) *Tree {
+	for _, child := range t.children {
+		if child.Value == plate && child.Sleeves < t.Sleeves {
+			return child
+		}
+	}
+	return nil
+}
+
+// AddSingles adds a plate loaded on one side only to every node in the tree
+// for each plate up to maxImbalance which is left over from plates after
+// loading the node. Trees with a single sleeve are not modified.
+func (t *Tree) AddSingles(plates []float32, maxImbalance float32) {
+	if t.Sleeves < 2 {
+		return
+	}
+	nodes := make([]*Tree, 0)
+	t.Walk(func(node *Tree) {
+		if node.Symmetric() {
+			nodes = append(nodes, node)
+		}
+	})
+
+	for _, node := range nodes {
+		left := make(map[float32]int)
+		for _, p := range plates {
+			left[p]++
+		}
+		for _, p := range node.Plates() {
+			left[p]--
+		}
+		for _, p := range plates {
+			if p <= maxImbalance && left[p] > 0 {
+				node.AddSingle(p)
+			}
+		}
+	}
+}
+
+// Symmetric returns true if the same plates are loaded on both sides.
+func (t *Tree) Symmetric() bool {
+	for node := t; node.Parent != nil; node = node.Parent {
+		if node.Sleeves < node.Parent.Sleeves {
+			return false
+		}
+	}
+	return true
+}
+
+// Left returns the plates loaded on the left side of the bar, in order.
+func (t *Tree) Left() []float32 {
+	return t.Plates()
+}
+
+// Right returns the plates loaded on the right side of the bar, in order.
+// Plates loaded on one side only are on the left.
+func (t *Tree) Right() []float32 {
+	plates := make([]float32, 0, t.Depth)
+	for _, node := range t.path() {
+		if node.Sleeves == node.Parent.Sleeves {
+			plates = append(plates, node.Value)
+		}
+	}
+	return plates
+}
+
+// path returns every node from the first plate to t.
+func (t *Tree) path() []*Tree {
+	nodes := make([]*Tree, t.Depth)
+	for node := t; node.Parent != nil; node = node.Parent {
+		nodes[node.Depth-1] = node
+	}
+	return nodes
+}
+
+// insertChild adds child to the sorted list of children.
+func (t *Tree) insertChild(child *Tree) {
+	i := sort.Search(len(t.children), func(i int) bool {
+		c := t.children[i]
+		return c.Value > child.Value || (c.Value == child.Value && c.Sleeves < child.Sleeves)
+	})
+	t.children = append(t.children, nil)
+	copy(t.children[i+1:], t.children[i:])
+	t.children[i] = child
+}
+
 type WalkTreeFn func(*Tree)
 
+// Walk calls fn for t and every descendant of t, depth first. Children are
+// visited in ascending order of plate value.
 func (t *Tree) Walk(fn WalkTreeFn) {
 	fn(t)
-	for _, child := range t.Children {
+	for _, child := range t.children {
 		child.Walk(fn)
 	}
 }
 
 type WalkNearbyTreeFn func(*Tree, int)
 
+// WalkNearby calls fn for every node within maxDistance plate changes of t
+// along with its distance from t. Children are visited in ascending order of
+// plate value before the parent.
 func (t *Tree) WalkNearby(maxDistance int, fn WalkNearbyTreeFn) {
 	seen := make(map[*Tree]bool)
 
@@ -114,7 +254,7 @@ func (t *Tree) WalkNearby(maxDistance int, fn WalkNearbyTreeFn) {
 		fn(t, distance)
 		seen[t] = true
 
-		for _, child := range t.Children {
+		for _, child := range t.children {
 			walk(child, distance+1)
 		}
 		walk(t.Parent, distance+1)
@@ -123,12 +263,32 @@ func (t *Tree) WalkNearby(maxDistance int, fn WalkNearbyTreeFn) {
 	walk(t, 0)
 }
 
+// Plates returns the plates loaded on each side of the bar, in order.
+func (t *Tree) Plates() []float32 {
+	plates := make([]float32, t.Depth)
+	for node := t; node.Parent != nil; node = node.Parent {
+		plates[node.Depth-1] = node.Value
+	}
+	return plates
+}
+
+// String returns the plates loaded on each side of the bar, or the plates on
+// the left and right sides if they are not the same. A side with no plates is
+// left out.
 func (t *Tree) String() string {
-	plates := make([]string, 0)
-	for parent := t; parent != nil; parent = parent.Parent {
-		if parent.Parent != nil {
-			plates = append([]string{fmt.Sprintf("%v", parent.Value)}, plates...)
+	if !t.Symmetric() {
+		if right := t.Right(); len(right) > 0 {
+			return fmt.Sprintf("left: %v; right: %v", formatPlates(t.Left()), formatPlates(right))
 		}
+		return fmt.Sprintf("left: %v", formatPlates(t.Left()))
+	}
+	return formatPlates(t.Plates())
+}
+
+func formatPlates(plates []float32) string {
+	s := make([]string, len(plates))
+	for i, p := range plates {
+		s[i] = fmt.Sprintf("%v", p)
 	}
-	return strings.Join(plates, ", ")
+	return strings.Join(s, ", ")
 }
@@ -1,6 +1,7 @@
 package platecalc
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,10 +11,12 @@ func TestProps(t *testing.T) {
 	tree := NewTree(nil, 45)
 	node := tree.Add(45, 35, 25)
 	assert.Equal(t, float32(25), node.Value)
-	assert.Equal(t, 255, node.TotalWeight())
+	assert.Equal(t, float32(255), node.TotalWeight())
 	assert.Equal(t, 3, node.Depth)
-	assert.Equal(t, 190, node.Score())
+	assert.Equal(t, 190, node.Score(true))
 	assert.Equal(t, "45, 35, 25", node.String())
+	assert.Equal(t, []float32{45, 35, 25}, node.Plates())
+	assert.Equal(t, []float32{}, tree.Plates())
 }
 
 func TestWalk(t *testing.T) {
@@ -38,6 +41,31 @@ func TestWalk(t *testing.T) {
 	assert.ElementsMatch(t, want, got)
 }
 
+func TestWalkOrder(t *testing.T) {
+	tree := NewTree(nil, 0)
+	tree.Add(3, 1)
+	tree.Add(1, 2)
+	tree.Add(2)
+	tree.Add(3, 2)
+
+	got := make([]string, 0)
+	tree.Walk(func(node *Tree) {
+		if node.Parent != nil {
+			got = append(got, node.String())
+		}
+	})
+
+	want := []string{
+		"1",
+		"1, 2",
+		"2",
+		"3",
+		"3, 1",
+		"3, 2",
+	}
+	assert.Equal(t, want, got)
+}
+
 func TestWalkNearby(t *testing.T) {
 	tree := NewTree(nil, 0)
 	tree.Add(1, 2, 3)
@@ -61,3 +89,49 @@ func TestWalkNearby(t *testing.T) {
 	}
 	assert.ElementsMatch(t, want, got)
 }
+
+func TestDistance(t *testing.T) {
+	tree := NewTree(nil, 0)
+	a := tree.Add(1, 2, 3)
+	b := tree.Add(1, 4)
+
+	assert.Equal(t, 0, a.Distance(a))
+	assert.Equal(t, 3, a.Distance(b))
+	assert.Equal(t, 3, b.Distance(a))
+	assert.Equal(t, 3, tree.Distance(a))
+}
+
+func TestAddSingles(t *testing.T) {
+	tree := NewTree(nil, 45)
+	for _, p := range Permutations(10, 2.5) {
+		tree.Add(p...)
+	}
+	tree.AddSingles([]float32{10, 2.5}, 2.5)
+
+	got := make([]string, 0)
+	tree.Walk(func(node *Tree) {
+		if node.Parent != nil {
+			got = append(got, fmt.Sprintf("%v: %v", node.TotalWeight(), node))
+		}
+	})
+
+	want := []string{
+		"50: 2.5",
+		"70: 2.5, 10",
+		"47.5: left: 2.5",
+		"65: 10",
+		"70: 10, 2.5",
+		"67.5: left: 10, 2.5; right: 10",
+	}
+	assert.Equal(t, want, got)
+
+	node := tree.Find(10).AddSingle(2.5)
+	assert.False(t, node.Symmetric())
+	assert.True(t, node.Parent.Symmetric())
+	assert.Equal(t, []float32{10, 2.5}, node.Left())
+	assert.Equal(t, []float32{10}, node.Right())
+
+	// plates on one side only are not in Children
+	assert.Equal(t, tree.Find(10, 2.5), tree.Find(10).Children[2.5])
+	assert.Equal(t, node, tree.Find(10).FindSingle(2.5))
+}
@@ -0,0 +1,101 @@
+package platecalc
+
+import (
+	"sort"
+)
+
+// Combinations returns every unique combination of plates of length 1 to N,
+// ignoring order. Each combination is sorted from heaviest to lightest plate.
+// Ex: [5, 5, 10] -> [[10], [10, 5], [10, 5, 5], [5], [5, 5]]
+func Combinations(plates ...float32) [][]float32 {
+	counts := make(map[float32]int)
+	values := make([]float32, 0)
+	for _, p := range plates {
+		if counts[p] == 0 {
+			values = append(values, p)
+		}
+		counts[p]++
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] > values[j] })
+
+	tuples := make([][]float32, 0)
+
+	var combine func(prefix []float32, values []float32)
+	combine = func(prefix []float32, values []float32) {
+		for i, p := range values {
+			tuple := prefix
+			for n := 0; n < counts[p]; n++ {
+				tuple = append(tuple[:len(tuple):len(tuple)], p)
+				tuples = append(tuples, tuple)
+				combine(tuple, values[i+1:])
+			}
+		}
+	}
+	combine(nil, values)
+
+	return tuples
+}
+
+// ReachableWeights returns the simplest loading for every total weight between
+// min and max which can be loaded on the bar of tree using plates, ordered by
+// total weight. Loadings missing from tree are added to it, so tree may be a
+// bare bar instead of a tree built from permutations of plates.
+func ReachableWeights(tree *Tree, plates []float32, min, max float32, opts *SolutionOpts) []*Tree {
+	collars := opts.collarWeight(tree)
+	best := make(map[float32]*Tree)
+
+	consider := func(node *Tree) {
+		total := node.TotalWeight() + collars
+		if total < min || total > max {
+			return
+		}
+		prev, ok := best[total]
+		if !ok {
+			best[total] = node
+			return
+		}
+		score, prevScore := node.Score(opts.PreferLessPlates), prev.Score(opts.PreferLessPlates)
+		if score < prevScore || (score == prevScore && node.Depth < prev.Depth) {
+			best[total] = node
+		}
+	}
+
+	consider(tree)
+	for _, combo := range Combinations(plates...) {
+		// plates ordered heaviest first have the lowest score
+		consider(tree.Add(combo...))
+	}
+
+	weights := make([]float32, 0, len(best))
+	for total := range best {
+		weights = append(weights, total)
+	}
+	sort.Slice(weights, func(i, j int) bool { return weights[i] < weights[j] })
+
+	solution := make([]*Tree, len(weights))
+	for i, total := range weights {
+		solution[i] = best[total]
+	}
+	return solution
+}
+
+// MissingWeights returns every weight between min and max in steps of inc
+// which is not in weights.
+func MissingWeights(weights []float32, min, max, inc float32) []float32 {
+	reachable := make(map[float32]bool)
+	for _, w := range weights {
+		reachable[w] = true
+	}
+
+	missing := make([]float32, 0)
+	for i := 0; ; i++ {
+		w := min + float32(i)*inc
+		if w > max {
+			break
+		}
+		if !reachable[w] {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
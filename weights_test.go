@@ -0,0 +1,49 @@
+package platecalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinations(t *testing.T) {
+	got := Combinations(5, 10, 5)
+	want := [][]float32{
+		{10},
+		{10, 5},
+		{10, 5, 5},
+		{5},
+		{5, 5},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestReachableWeights(t *testing.T) {
+	tree := NewTree(nil, 45)
+	result := ReachableWeights(tree, []float32{10, 5, 5, 2.5}, 45, 80, &SolutionOpts{})
+
+	got := make([]string, 0)
+	weights := make([]float32, 0)
+	for _, node := range result {
+		got = append(got, node.String())
+		weights = append(weights, node.TotalWeight())
+	}
+
+	want := []string{
+		"",
+		"2.5",
+		"5",
+		"5, 2.5",
+		"10",
+		"10, 2.5",
+		"10, 5",
+		"10, 5, 2.5",
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, []float32{45, 50, 55, 60, 65, 70, 75, 80}, weights)
+}
+
+func TestMissingWeights(t *testing.T) {
+	got := MissingWeights([]float32{45, 50, 60}, 45, 65, 5)
+	assert.Equal(t, []float32{55, 65}, got)
+}
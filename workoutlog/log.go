@@ -0,0 +1,251 @@
+// Package workoutlog records the sets actually lifted and compares them with
+// a generated plan.
+package workoutlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is the format of dates in a log.
+const DateFormat = "2006-01-02"
+
+// Entry is one set recorded in a log. Week and Day are the plan row the set
+// was lifted for, or zero if it wasn't part of the plan.
+type Entry struct {
+	Date   time.Time
+	Week   int
+	Day    int
+	Lift   string
+	Weight float32
+	Reps   int
+	RPE    float32 // Rating of perceived exertion, zero if not recorded
+	Notes  string
+}
+
+var csvHeader = []string{"Date", "Week", "Day", "Lift", "Weight", "Reps", "RPE", "Notes"}
+
+// jsonEntry is an Entry as a line of a JSON lines log.
+type jsonEntry struct {
+	Date   string  `json:"date"`
+	Week   int     `json:"week,omitempty"`
+	Day    int     `json:"day,omitempty"`
+	Lift   string  `json:"lift"`
+	Weight float32 `json:"weight"`
+	Reps   int     `json:"reps"`
+	RPE    float32 `json:"rpe,omitempty"`
+	Notes  string  `json:"notes,omitempty"`
+}
+
+// isJSON returns whether the log at path is JSON lines rather than CSV.
+func isJSON(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".jsonl" || ext == ".json"
+}
+
+// Load reads the log at path. Files ending in .jsonl or .json are read as
+// JSON lines and anything else as CSV with a header.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if isJSON(path) {
+		return ReadJSON(f)
+	}
+	return ReadCSV(f)
+}
+
+// Append adds entries to the end of the log at path, creating it if it
+// doesn't exist.
+func Append(path string, entries ...Entry) error {
+	info, err := os.Stat(path)
+	empty := os.IsNotExist(err) || (err == nil && info.Size() == 0)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if isJSON(path) {
+		err = WriteJSON(f, entries)
+	} else {
+		err = WriteCSV(f, entries, empty)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadCSV reads a log with a header row. Columns are found by name so they
+// may be in any order, and RPE and Notes may be left out.
+func ReadCSV(r io.Reader) ([]Entry, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	cols := columns(records[0])
+	for _, name := range []string{"Date", "Lift", "Weight", "Reps"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing column: %v", name)
+		}
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for i, record := range records[1:] {
+		get := func(name string) string {
+			if j, ok := cols[name]; ok && j < len(record) {
+				return strings.TrimSpace(record[j])
+			}
+			return ""
+		}
+		e, err := parseEntry(get("Date"), get("Week"), get("Day"), get("Lift"), get("Weight"), get("Reps"), get("RPE"))
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+2, err)
+		}
+		e.Notes = get("Notes")
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// WriteCSV writes entries as CSV, preceded by a header row if header is true.
+func WriteCSV(w io.Writer, entries []Entry, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		cw.Write(csvHeader)
+	}
+	for _, e := range entries {
+		cw.Write([]string{
+			e.Date.Format(DateFormat),
+			optional(e.Week),
+			optional(e.Day),
+			e.Lift,
+			fmt.Sprintf("%v", e.Weight),
+			fmt.Sprintf("%v", e.Reps),
+			optional(e.RPE),
+			e.Notes,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadJSON reads a log with one JSON object per line. Blank lines are
+// skipped.
+func ReadJSON(r io.Reader) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var je jsonEntry
+		if err := json.Unmarshal(scanner.Bytes(), &je); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		date, err := time.Parse(DateFormat, je.Date)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid date: %v", line, je.Date)
+		}
+		e := Entry{date, je.Week, je.Day, je.Lift, je.Weight, je.Reps, je.RPE, je.Notes}
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// WriteJSON writes entries as JSON lines.
+func WriteJSON(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		err := enc.Encode(&jsonEntry{e.Date.Format(DateFormat), e.Week, e.Day, e.Lift, e.Weight, e.Reps, e.RPE, e.Notes})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseEntry(date, week, day, lift, weight, reps, rpe string) (Entry, error) {
+	var e Entry
+	var err error
+	if e.Date, err = time.Parse(DateFormat, date); err != nil {
+		return e, fmt.Errorf("invalid date: %v", date)
+	}
+	if week != "" {
+		if e.Week, err = strconv.Atoi(week); err != nil {
+			return e, fmt.Errorf("invalid week: %v", week)
+		}
+	}
+	if day != "" {
+		if e.Day, err = strconv.Atoi(day); err != nil {
+			return e, fmt.Errorf("invalid day: %v", day)
+		}
+	}
+	e.Lift = lift
+	f, err := strconv.ParseFloat(weight, 32)
+	if err != nil {
+		return e, fmt.Errorf("invalid weight: %v", weight)
+	}
+	e.Weight = float32(f)
+	if e.Reps, err = strconv.Atoi(reps); err != nil {
+		return e, fmt.Errorf("invalid reps: %v", reps)
+	}
+	if rpe != "" {
+		f, err := strconv.ParseFloat(rpe, 32)
+		if err != nil {
+			return e, fmt.Errorf("invalid RPE: %v", rpe)
+		}
+		e.RPE = float32(f)
+	}
+	return e, e.validate()
+}
+
+func (e *Entry) validate() error {
+	switch {
+	case e.Lift == "":
+		return fmt.Errorf("lift is required")
+	case e.Weight < 0:
+		return fmt.Errorf("weight must not be negative")
+	case e.Reps < 0:
+		return fmt.Errorf("reps must not be negative")
+	case e.RPE < 0 || e.RPE > 10:
+		return fmt.Errorf("RPE must be between 0 and 10")
+	}
+	return nil
+}
+
+// columns returns the index of each column in header.
+func columns(header []string) map[string]int {
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+	return cols
+}
+
+// optional formats n, or an empty string if n is zero.
+func optional(n interface{}) string {
+	s := fmt.Sprintf("%v", n)
+	if s == "0" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package workoutlog
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestReadCSV(t *testing.T) {
+	log := strings.Join([]string{
+		"Date,Lift,Weight,Reps,Week,Day,RPE,Notes",
+		"2026-10-19,Squat,225,5,1,1,8,felt good",
+		"2026-10-20,Curl,30,12,,,,",
+	}, "\n")
+	entries, err := ReadCSV(strings.NewReader(log))
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{
+		{date("2026-10-19"), 1, 1, "Squat", 225, 5, 8, "felt good"},
+		{date("2026-10-20"), 0, 0, "Curl", 30, 12, 0, ""},
+	}, entries)
+
+	_, err = ReadCSV(strings.NewReader("Date,Lift,Weight\n2026-10-19,Squat,225\n"))
+	assert.EqualError(t, err, "missing column: Reps")
+
+	_, err = ReadCSV(strings.NewReader("Date,Lift,Weight,Reps\n2026-10-19,Squat,heavy,5\n"))
+	assert.EqualError(t, err, "line 2: invalid weight: heavy")
+}
+
+func TestAppend(t *testing.T) {
+	entries := []Entry{
+		{date("2026-10-19"), 1, 1, "Squat", 225, 5, 8, "felt good, fast"},
+		{date("2026-10-19"), 1, 1, "Squat", 225, 4, 0, ""},
+	}
+
+	for _, name := range []string{"log.csv", "log.jsonl"} {
+		path := filepath.Join(t.TempDir(), name)
+		assert.Nil(t, Append(path, entries[0]))
+		assert.Nil(t, Append(path, entries[1]))
+
+		got, err := Load(path)
+		assert.Nil(t, err)
+		assert.Equal(t, entries, got, name)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Entry{{date("2026-10-19"), 0, 0, "Press", 95.5, 8, 0, ""}}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "Date,Week,Day,Lift,Weight,Reps,RPE,Notes\n2026-10-19,,,Press,95.5,8,,\n", buf.String())
+}
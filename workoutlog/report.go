@@ -0,0 +1,258 @@
+package workoutlog
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlannedSet is one set from a plan written by the plan command.
+type PlannedSet struct {
+	Week   int
+	Day    int
+	Lift   string
+	Weight float32
+	Reps   int
+	AMRAP  bool // As many reps as possible, with Reps as the minimum
+}
+
+// ReadPlan reads the sets of a plan CSV. Each row with more than one set is
+// expanded to one PlannedSet per set.
+func ReadPlan(r io.Reader) ([]PlannedSet, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	cols := columns(records[0])
+	for _, name := range []string{"Week", "Day", "Lift", "Weight", "Sets", "Reps"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing column: %v", name)
+		}
+	}
+
+	sets := make([]PlannedSet, 0)
+	for i, record := range records[1:] {
+		var s PlannedSet
+		var n int
+		var f float64
+		var err error
+		line := i + 2
+		if s.Week, err = strconv.Atoi(record[cols["Week"]]); err != nil {
+			return nil, fmt.Errorf("line %v: invalid week: %v", line, record[cols["Week"]])
+		}
+		if s.Day, err = strconv.Atoi(record[cols["Day"]]); err != nil {
+			return nil, fmt.Errorf("line %v: invalid day: %v", line, record[cols["Day"]])
+		}
+		s.Lift = record[cols["Lift"]]
+		if f, err = strconv.ParseFloat(record[cols["Weight"]], 32); err != nil {
+			return nil, fmt.Errorf("line %v: invalid weight: %v", line, record[cols["Weight"]])
+		}
+		s.Weight = float32(f)
+		reps := record[cols["Reps"]]
+		s.AMRAP = strings.HasSuffix(reps, "+")
+		if s.Reps, err = strconv.Atoi(strings.TrimSuffix(reps, "+")); err != nil {
+			return nil, fmt.Errorf("line %v: invalid reps: %v", line, record[cols["Reps"]])
+		}
+		if n, err = strconv.Atoi(record[cols["Sets"]]); err != nil {
+			return nil, fmt.Errorf("line %v: invalid sets: %v", line, record[cols["Sets"]])
+		}
+		for j := 0; j < n; j++ {
+			sets = append(sets, s)
+		}
+	}
+	return sets, nil
+}
+
+// MissedSet is a planned set which wasn't completed. Reps is the reps logged
+// at the planned weight, or zero if the set wasn't attempted.
+type MissedSet struct {
+	PlannedSet
+	Reps int
+}
+
+func (s PlannedSet) String() string {
+	str := fmt.Sprintf("week %v day %v %v %vx%v", s.Week, s.Day, s.Lift, s.Weight, s.Reps)
+	if s.AMRAP {
+		return str + "+"
+	}
+	return str
+}
+
+func (m MissedSet) String() string {
+	s := m.PlannedSet.String()
+	if m.Reps > 0 {
+		return fmt.Sprintf("%v (did %v)", s, m.Reps)
+	}
+	return s
+}
+
+// Report compares a log with a plan.
+type Report struct {
+	Planned   int // Sets planned up to the last logged day
+	Completed int // Planned sets lifted for at least the planned reps
+	Missed    []MissedSet
+	PRs       []PR
+}
+
+// Adherence returns the fraction of planned sets which were completed.
+func (r *Report) Adherence() float32 {
+	if r.Planned == 0 {
+		return 0
+	}
+	return float32(r.Completed) / float32(r.Planned)
+}
+
+type planDay struct {
+	week, day int
+	lift      string
+}
+
+// Compare matches logged sets with the planned sets of the same week, day and
+// lift. A planned set is completed by a logged set of its weight with at
+// least its reps. Days after the last logged week and day haven't happened
+// yet and are left out.
+func Compare(plan []PlannedSet, entries []Entry) *Report {
+	logged := make(map[planDay][]Entry)
+	lastWeek, lastDay := 0, 0
+	for _, e := range entries {
+		if e.Week == 0 {
+			continue
+		}
+		key := planDay{e.Week, e.Day, e.Lift}
+		logged[key] = append(logged[key], e)
+		if e.Week > lastWeek || (e.Week == lastWeek && e.Day > lastDay) {
+			lastWeek, lastDay = e.Week, e.Day
+		}
+	}
+
+	report := &Report{PRs: PRs(entries)}
+	used := make(map[planDay][]bool)
+	for _, s := range plan {
+		if s.Week > lastWeek || (s.Week == lastWeek && s.Day > lastDay) {
+			continue
+		}
+		report.Planned++
+
+		key := planDay{s.Week, s.Day, s.Lift}
+		sets := logged[key]
+		if used[key] == nil {
+			used[key] = make([]bool, len(sets))
+		}
+
+		// a set with too few reps is only counted against one planned set
+		match, partial := -1, -1
+		for i, e := range sets {
+			if used[key][i] || e.Weight != s.Weight {
+				continue
+			}
+			if e.Reps >= s.Reps {
+				match = i
+				break
+			}
+			if partial < 0 || e.Reps > sets[partial].Reps {
+				partial = i
+			}
+		}
+		switch {
+		case match >= 0:
+			used[key][match] = true
+			report.Completed++
+		case partial >= 0:
+			used[key][partial] = true
+			report.Missed = append(report.Missed, MissedSet{s, sets[partial].Reps})
+		default:
+			report.Missed = append(report.Missed, MissedSet{s, 0})
+		}
+	}
+	return report
+}
+
+// E1RM returns the estimated one rep max for reps at weight with the Epley
+// formula. Reps in reserve are counted when rpe is recorded, so 5 reps at
+// RPE 8 is estimated as 7 reps to failure.
+func E1RM(weight float32, reps int, rpe float32) float32 {
+	r := float32(reps)
+	if rpe > 0 {
+		r += 10 - rpe
+	}
+	if r <= 1 {
+		return weight
+	}
+	return weight * (1 + r/30)
+}
+
+// PR is a logged set which beat every earlier set of the same lift.
+type PR struct {
+	Entry
+	E1RM      float32 // Estimated one rep max of the set
+	BestE1RM  bool    // The set has the highest estimated one rep max so far
+	RepRecord bool    // No earlier set was as heavy for as many reps
+}
+
+func (pr PR) String() string {
+	kinds := make([]string, 0, 2)
+	if pr.RepRecord {
+		kinds = append(kinds, fmt.Sprintf("%v rep max", pr.Reps))
+	}
+	if pr.BestE1RM {
+		kinds = append(kinds, fmt.Sprintf("e1RM %.0f", pr.E1RM))
+	}
+	return fmt.Sprintf("%v %v %vx%v (%v)", pr.Date.Format(DateFormat), pr.Lift, pr.Weight, pr.Reps, strings.Join(kinds, ", "))
+}
+
+// PRs returns the sets which beat every set of the same lift from an earlier
+// date, in date order. Sets from the same day aren't compared so warm-ups
+// leading up to a top set aren't PRs. No set on the first day of a lift is a
+// PR since there is nothing to beat.
+func PRs(entries []Entry) []PR {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	prs := make([]PR, 0)
+	bestE1RM := make(map[string]float32)
+	history := make(map[string][]Entry)
+	// today holds the sets of the current date until the next date starts
+	today := make([]Entry, 0)
+	for i, e := range sorted {
+		if i > 0 && !e.Date.Equal(sorted[i-1].Date) {
+			for _, t := range today {
+				history[t.Lift] = append(history[t.Lift], t)
+				if e1rm := E1RM(t.Weight, t.Reps, t.RPE); e1rm > bestE1RM[t.Lift] {
+					bestE1RM[t.Lift] = e1rm
+				}
+			}
+			today = today[:0]
+		}
+		if e.Reps == 0 {
+			continue
+		}
+		today = append(today, e)
+		prev := history[e.Lift]
+		if len(prev) == 0 {
+			continue
+		}
+
+		e1rm := E1RM(e.Weight, e.Reps, e.RPE)
+		pr := PR{Entry: e, E1RM: e1rm, BestE1RM: e1rm > bestE1RM[e.Lift], RepRecord: true}
+		for _, p := range prev {
+			if p.Reps >= e.Reps && p.Weight >= e.Weight {
+				pr.RepRecord = false
+				break
+			}
+		}
+		if pr.BestE1RM || pr.RepRecord {
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
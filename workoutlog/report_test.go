@@ -0,0 +1,94 @@
+package workoutlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPlan = strings.Join([]string{
+	"Lift,Week,Day,TM %,Weight,Plates,Sets,Reps,Changes",
+	"Squat,1,1,65%,180,\"35, 25, 5, 2.5\",1,5,add 35",
+	"Squat,1,1,60%,165,\"35, 25\",2,10,remove 5",
+	"Bench,1,2,65%,120,\"25, 10\",1,5,add 25",
+	"Squat,2,1,70%,190,\"45, 25\",1,3+,add 45",
+}, "\n")
+
+func TestReadPlan(t *testing.T) {
+	plan, err := ReadPlan(strings.NewReader(testPlan))
+	assert.Nil(t, err)
+	assert.Equal(t, []PlannedSet{
+		{1, 1, "Squat", 180, 5, false},
+		{1, 1, "Squat", 165, 10, false},
+		{1, 1, "Squat", 165, 10, false},
+		{1, 2, "Bench", 120, 5, false},
+		{2, 1, "Squat", 190, 3, true},
+	}, plan)
+}
+
+func TestCompare(t *testing.T) {
+	plan, err := ReadPlan(strings.NewReader(testPlan))
+	assert.Nil(t, err)
+
+	entries := []Entry{
+		{Date: date("2026-10-19"), Week: 1, Day: 1, Lift: "Squat", Weight: 180, Reps: 6},
+		{Date: date("2026-10-19"), Week: 1, Day: 1, Lift: "Squat", Weight: 165, Reps: 10},
+		{Date: date("2026-10-19"), Week: 1, Day: 1, Lift: "Squat", Weight: 165, Reps: 7},
+		{Date: date("2026-10-21"), Week: 1, Day: 2, Lift: "Bench", Weight: 120, Reps: 5},
+		{Date: date("2026-10-21"), Lift: "Curl", Weight: 30, Reps: 12},
+	}
+	report := Compare(plan, entries)
+
+	// week 2 hasn't been logged yet
+	assert.Equal(t, 4, report.Planned)
+	assert.Equal(t, 3, report.Completed)
+	assert.Equal(t, float32(0.75), report.Adherence())
+	assert.Equal(t, []MissedSet{{PlannedSet{1, 1, "Squat", 165, 10, false}, 7}}, report.Missed)
+	assert.Equal(t, "week 1 day 1 Squat 165x10 (did 7)", report.Missed[0].String())
+}
+
+func TestE1RM(t *testing.T) {
+	assert.Equal(t, float32(300), E1RM(300, 1, 0))
+	assert.InDelta(t, 200, E1RM(150, 10, 0), 0.001)
+	// 5 reps with 5 in reserve
+	assert.InDelta(t, 200, E1RM(150, 5, 5), 0.001)
+}
+
+func TestPRs(t *testing.T) {
+	entries := []Entry{
+		{Date: date("2026-10-26"), Lift: "Squat", Weight: 200, Reps: 8},
+		{Date: date("2026-10-19"), Lift: "Squat", Weight: 225, Reps: 5},
+		{Date: date("2026-10-19"), Lift: "Bench", Weight: 135, Reps: 5},
+		{Date: date("2026-10-22"), Lift: "Squat", Weight: 225, Reps: 3},
+		{Date: date("2026-10-29"), Lift: "Squat", Weight: 235, Reps: 5},
+	}
+	got := make([]string, 0)
+	for _, pr := range PRs(entries) {
+		got = append(got, pr.String())
+	}
+	assert.Equal(t, []string{
+		"2026-10-26 Squat 200x8 (8 rep max)",
+		"2026-10-29 Squat 235x5 (5 rep max, e1RM 274)",
+	}, got)
+}
+
+func TestPRsSameDay(t *testing.T) {
+	entries := []Entry{
+		{Date: date("2026-10-19"), Lift: "Squat", Weight: 135, Reps: 5},
+		{Date: date("2026-10-19"), Lift: "Squat", Weight: 185, Reps: 5},
+		{Date: date("2026-10-19"), Lift: "Squat", Weight: 225, Reps: 5},
+		{Date: date("2026-10-22"), Lift: "Squat", Weight: 135, Reps: 5},
+		{Date: date("2026-10-22"), Lift: "Squat", Weight: 185, Reps: 5},
+		{Date: date("2026-10-22"), Lift: "Squat", Weight: 225, Reps: 5},
+		{Date: date("2026-10-22"), Lift: "Squat", Weight: 230, Reps: 5},
+	}
+	got := make([]string, 0)
+	for _, pr := range PRs(entries) {
+		got = append(got, pr.String())
+	}
+	// only sets beating the first day are PRs, not the warm-ups of either day
+	assert.Equal(t, []string{
+		"2026-10-22 Squat 230x5 (5 rep max, e1RM 268)",
+	}, got)
+}
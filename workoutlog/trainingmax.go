@@ -0,0 +1,144 @@
+package workoutlog
+
+import (
+	"fmt"
+)
+
+// Action is how a training max changes for the next cycle.
+type Action string
+
+const (
+	Increase Action = "increase"
+	Hold     Action = "hold"
+	Reset    Action = "reset"
+)
+
+// Rules decide how each lift's training max changes for the next cycle from
+// the AMRAP sets of the last one. Zero fields use the defaults.
+type Rules struct {
+	Increments       map[string]float32 `yaml:"Increments"`       // Increase for each lift (default 10 for Squat and Deadlift, 5 otherwise)
+	MinExtraReps     int                `yaml:"MinExtraReps"`     // Reps beyond the minimum needed on every AMRAP set to increase
+	ResetPercent     float32            `yaml:"ResetPercent"`     // Percent the training max drops when an AMRAP set misses its minimum (default 10)
+	MaxPercentOfE1RM float32            `yaml:"MaxPercentOfE1RM"` // Hold rather than increase above this percent of the best e1RM (default 100)
+}
+
+var defaultIncrements = map[string]float32{
+	"Squat":    10,
+	"Deadlift": 10,
+}
+
+func (r *Rules) increment(lift string) float32 {
+	if inc, ok := r.Increments[lift]; ok {
+		return inc
+	}
+	if inc, ok := defaultIncrements[lift]; ok {
+		return inc
+	}
+	return 5
+}
+
+func (r *Rules) resetPercent() float32 {
+	if r.ResetPercent == 0 {
+		return 10
+	}
+	return r.ResetPercent
+}
+
+func (r *Rules) maxPercentOfE1RM() float32 {
+	if r.MaxPercentOfE1RM == 0 {
+		return 100
+	}
+	return r.MaxPercentOfE1RM
+}
+
+// AMRAPResult is a planned AMRAP set and the set logged for it.
+type AMRAPResult struct {
+	Planned PlannedSet
+	Logged  Entry
+	E1RM    float32
+}
+
+// AMRAPResults returns the logged result of each planned AMRAP set, by lift.
+// The logged set is the one with the most reps at the planned weight on the
+// same week and day. Planned sets which weren't logged are left out.
+func AMRAPResults(plan []PlannedSet, entries []Entry) map[string][]AMRAPResult {
+	results := make(map[string][]AMRAPResult)
+	for _, s := range plan {
+		if !s.AMRAP {
+			continue
+		}
+		best := -1
+		for i, e := range entries {
+			if e.Week != s.Week || e.Day != s.Day || e.Lift != s.Lift || e.Weight != s.Weight {
+				continue
+			}
+			if best < 0 || e.Reps > entries[best].Reps {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		e := entries[best]
+		results[s.Lift] = append(results[s.Lift], AMRAPResult{s, e, E1RM(e.Weight, e.Reps, e.RPE)})
+	}
+	return results
+}
+
+// Adjustment is the change to a lift's training max for the next cycle.
+type Adjustment struct {
+	Lift           string
+	Action         Action
+	TrainingMax    float32
+	NewTrainingMax float32
+	E1RM           float32 // Best estimated one rep max of the AMRAP sets
+	Reason         string
+}
+
+func (a Adjustment) String() string {
+	return fmt.Sprintf("%v: %v %v -> %v (%v)", a.Lift, a.Action, a.TrainingMax, a.NewTrainingMax, a.Reason)
+}
+
+// Adjust returns the training max for lift in the next cycle. The training
+// max is reset if any AMRAP set missed its minimum reps, increased if every
+// AMRAP set beat its minimum by MinExtraReps and the increase stays within
+// MaxPercentOfE1RM of the best e1RM, and held otherwise.
+func (r *Rules) Adjust(lift string, trainingMax float32, results []AMRAPResult) Adjustment {
+	a := Adjustment{Lift: lift, Action: Hold, TrainingMax: trainingMax, NewTrainingMax: trainingMax}
+	if len(results) == 0 {
+		a.Reason = "no AMRAP sets logged"
+		return a
+	}
+
+	for _, res := range results {
+		if res.E1RM > a.E1RM {
+			a.E1RM = res.E1RM
+		}
+	}
+
+	for _, res := range results {
+		if res.Logged.Reps < res.Planned.Reps {
+			a.Action = Reset
+			a.NewTrainingMax = trainingMax * (1 - r.resetPercent()/100)
+			a.Reason = fmt.Sprintf("week %v: %v reps at %v, needed %v", res.Planned.Week, res.Logged.Reps, res.Planned.Weight, res.Planned.Reps)
+			return a
+		}
+	}
+
+	for _, res := range results {
+		if needed := res.Planned.Reps + r.MinExtraReps; res.Logged.Reps < needed {
+			a.Reason = fmt.Sprintf("week %v: %v reps at %v, needed %v to increase", res.Planned.Week, res.Logged.Reps, res.Planned.Weight, needed)
+			return a
+		}
+	}
+
+	next := trainingMax + r.increment(lift)
+	if limit := a.E1RM * r.maxPercentOfE1RM() / 100; next > limit {
+		a.Reason = fmt.Sprintf("%v is above %v%% of e1RM %.0f", next, r.maxPercentOfE1RM(), a.E1RM)
+		return a
+	}
+	a.Action = Increase
+	a.NewTrainingMax = next
+	a.Reason = fmt.Sprintf("e1RM %.0f", a.E1RM)
+	return a
+}
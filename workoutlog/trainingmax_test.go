@@ -0,0 +1,66 @@
+package workoutlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAMRAPResults(t *testing.T) {
+	plan := []PlannedSet{
+		{1, 1, "Squat", 180, 5, true},
+		{1, 1, "Squat", 165, 10, false},
+		{2, 1, "Squat", 190, 3, true},
+		{3, 1, "Squat", 200, 1, true},
+	}
+	entries := []Entry{
+		{Week: 1, Day: 1, Lift: "Squat", Weight: 180, Reps: 7},
+		{Week: 1, Day: 1, Lift: "Squat", Weight: 180, Reps: 8},
+		{Week: 1, Day: 1, Lift: "Squat", Weight: 165, Reps: 10},
+		{Week: 2, Day: 1, Lift: "Squat", Weight: 190, Reps: 6},
+	}
+	results := AMRAPResults(plan, entries)
+	assert.Len(t, results["Squat"], 2)
+	assert.Equal(t, 8, results["Squat"][0].Logged.Reps)
+	assert.InDelta(t, 228, results["Squat"][0].E1RM, 0.001)
+	assert.Equal(t, 6, results["Squat"][1].Logged.Reps)
+}
+
+func TestAdjust(t *testing.T) {
+	amrap := func(weight float32, minReps, reps int) AMRAPResult {
+		return AMRAPResult{
+			Planned: PlannedSet{Week: 1, Day: 1, Lift: "Squat", Weight: weight, Reps: minReps, AMRAP: true},
+			Logged:  Entry{Week: 1, Day: 1, Lift: "Squat", Weight: weight, Reps: reps},
+			E1RM:    E1RM(weight, reps, 0),
+		}
+	}
+	rules := &Rules{}
+
+	a := rules.Adjust("Squat", 200, []AMRAPResult{amrap(170, 5, 8)})
+	assert.Equal(t, Increase, a.Action)
+	assert.Equal(t, float32(210), a.NewTrainingMax)
+
+	a = rules.Adjust("Bench", 200, []AMRAPResult{amrap(170, 5, 8)})
+	assert.Equal(t, float32(205), a.NewTrainingMax)
+
+	a = rules.Adjust("Squat", 200, nil)
+	assert.Equal(t, Hold, a.Action)
+	assert.Equal(t, "no AMRAP sets logged", a.Reason)
+
+	// the minimum reps were made but the e1RM is too low to increase
+	a = rules.Adjust("Squat", 200, []AMRAPResult{amrap(170, 5, 5)})
+	assert.Equal(t, Hold, a.Action)
+	assert.Equal(t, "Squat: hold 200 -> 200 (210 is above 100% of e1RM 198)", a.String())
+
+	a = rules.Adjust("Squat", 200, []AMRAPResult{amrap(170, 5, 8), amrap(190, 1, 0)})
+	assert.Equal(t, Reset, a.Action)
+	assert.Equal(t, float32(180), a.NewTrainingMax)
+	assert.Equal(t, "week 1: 0 reps at 190, needed 1", a.Reason)
+
+	rules = &Rules{MinExtraReps: 4, Increments: map[string]float32{"Squat": 5}}
+	a = rules.Adjust("Squat", 200, []AMRAPResult{amrap(170, 5, 8)})
+	assert.Equal(t, Hold, a.Action)
+	assert.Equal(t, "week 1: 8 reps at 170, needed 9 to increase", a.Reason)
+	a = rules.Adjust("Squat", 200, []AMRAPResult{amrap(170, 5, 9)})
+	assert.Equal(t, float32(205), a.NewTrainingMax)
+}